@@ -0,0 +1,98 @@
+package connector
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/mbeoliero/kit/log"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type namedCloser struct {
+	name    string
+	closers []io.Closer
+}
+
+var (
+	closersMu sync.Mutex
+	closers   []namedCloser
+)
+
+// Register adds closer to the registry closed by CloseAll. Registering a name again keeps the previous
+// closers, they're closed along with the new one, the latest first. The Init* constructors register the
+// handles they return, named after the kind, the address and the database, e.g. "mysql:10.0.0.1:3306/app".
+func Register(name string, closer io.Closer) {
+	if closer == nil {
+		return
+	}
+	closersMu.Lock()
+	defer closersMu.Unlock()
+	for i := range closers {
+		if closers[i].name == name {
+			closers[i].closers = append(closers[i].closers, closer)
+			return
+		}
+	}
+	closers = append(closers, namedCloser{name: name, closers: []io.Closer{closer}})
+}
+
+// CloseAll closes every registered closer in the reverse order of registration and empties
+// the registry. A closer still running when ctx is done is abandoned with ctx.Err(), all
+// errors are joined into the returned one.
+func CloseAll(ctx context.Context) error {
+	closersMu.Lock()
+	all := closers
+	closers = nil
+	closersMu.Unlock()
+
+	var errs []error
+	for i := len(all) - 1; i >= 0; i-- {
+		for j := len(all[i].closers) - 1; j >= 0; j-- {
+			if err := closeWithContext(ctx, all[i].closers[j]); err != nil {
+				log.CtxError(ctx, "close %s failed with error %v", all[i].name, err)
+				errs = append(errs, fmt.Errorf("close %s: %w", all[i].name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func closeWithContext(ctx context.Context, closer io.Closer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- closer.Close()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// sqlPools closes the pools of a gorm db, the one of the db and the dbresolver ones
+type sqlPools []*sql.DB
+
+func (p sqlPools) Close() error {
+	var errs []error
+	for _, db := range p {
+		errs = append(errs, db.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// mongoCloser adapts a mongo client, which disconnects with a context, to io.Closer
+type mongoCloser struct {
+	cli *mongo.Client
+}
+
+func (c mongoCloser) Close() error {
+	return c.cli.Disconnect(context.Background())
+}
@@ -0,0 +1,51 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error { return f() }
+
+func TestCloseAll(t *testing.T) {
+	var order []string
+	record := func(name string, err error) closerFunc {
+		return func() error {
+			order = append(order, name)
+			return err
+		}
+	}
+	errB := errors.New("b failed")
+
+	Register("a", record("a", nil))
+	Register("b", record("b", errB))
+	Register("c", record("c", nil))
+	Register("a", record("a2", nil))
+
+	err := CloseAll(context.Background())
+	assert.ErrorIs(t, err, errB)
+	assert.Equal(t, []string{"c", "b", "a2", "a"}, order)
+
+	order = nil
+	assert.NoError(t, CloseAll(context.Background()))
+	assert.Empty(t, order)
+}
+
+func TestCloseAllTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	Register("slow", closerFunc(func() error {
+		<-release
+		return nil
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, CloseAll(ctx), context.DeadlineExceeded)
+}
@@ -1,40 +1,55 @@
 package connector
 
 type MysqlConfig struct {
-	Path            string `json:"path" yaml:"path" mapstructure:"path"`                   // 服务器地址:端口
-	WritePath       string `json:"write_path" yaml:"write_path" mapstructure:"write_path"` // 服务器地址:端口
-	ReadPath        string `json:"read_path" yaml:"read_path" mapstructure:"read_path"`
-	Config          string `json:"config" yaml:"config" mapstructure:"config"`                                  // 高级配置
-	Dbname          string `json:"db_name" yaml:"db_name" mapstructure:"dbname"`                                // 数据库名
-	Username        string `json:"username" yaml:"username" mapstructure:"username"`                            // 数据库用户名
-	Password        string `json:"password" yaml:"password" mapstructure:"password"`                            // 数据库密码
-	MaxIdleConns    int    `json:"max_idle_conns" yaml:"max_idle_conns" mapstructure:"max_idle_conns"`          // 空闲中的最大连接数
-	MaxOpenConns    int    `json:"max_open_conns" yaml:"max_open_conns" mapstructure:"max_open_conns"`          // 打开到数据库的最大连接数
-	ConnMaxLifetime int    `json:"conn_max_lifetime" yaml:"conn_max_lifetime" mapstructure:"conn_max_lifetime"` // 空闲链接生命周期，单位秒钟
-	DisableTrace    bool   `json:"disable_trace" yaml:"disable_trace" mapstructure:"disable_trace"`             // 是否会禁用 Trace
-	DisableLog      bool   `json:"disable_log" yaml:"disable_log" mapstructure:"disable_log"`
+	Path            string    `json:"path" yaml:"path" mapstructure:"path"`                   // 服务器地址:端口
+	WritePath       string    `json:"write_path" yaml:"write_path" mapstructure:"write_path"` // 服务器地址:端口
+	ReadPath        string    `json:"read_path" yaml:"read_path" mapstructure:"read_path"`
+	Config          string    `json:"config" yaml:"config" mapstructure:"config"`                                  // 高级配置
+	Dbname          string    `json:"db_name" yaml:"db_name" mapstructure:"dbname"`                                // 数据库名
+	Username        string    `json:"username" yaml:"username" mapstructure:"username"`                            // 数据库用户名
+	Password        string    `json:"password" yaml:"password" mapstructure:"password"`                            // 数据库密码
+	MaxIdleConns    int       `json:"max_idle_conns" yaml:"max_idle_conns" mapstructure:"max_idle_conns"`          // 空闲中的最大连接数
+	MaxOpenConns    int       `json:"max_open_conns" yaml:"max_open_conns" mapstructure:"max_open_conns"`          // 打开到数据库的最大连接数
+	ConnMaxLifetime int       `json:"conn_max_lifetime" yaml:"conn_max_lifetime" mapstructure:"conn_max_lifetime"` // 空闲链接生命周期，单位秒钟
+	DisableTrace    bool      `json:"disable_trace" yaml:"disable_trace" mapstructure:"disable_trace"`             // 是否会禁用 Trace
+	DisableLog      bool      `json:"disable_log" yaml:"disable_log" mapstructure:"disable_log"`
+	TLS             TLSConfig `json:"tls" yaml:"tls" mapstructure:"tls"` // tls 证书配置，为空时不开启
 }
 
 type MongoConfig struct {
-	Database     string `json:"database" yaml:"database" mapstructure:"database"`
-	Address      string `json:"address" yaml:"address" mapstructure:"address"`
-	Username     string `json:"username" yaml:"username" mapstructure:"username"`
-	Password     string `json:"password" yaml:"password" mapstructure:"password"`
-	EnableTLS    bool   `json:"enable_tls" yaml:"enable_tls" mapstructure:"enable_tls"` // 是否开启 tls
-	Cfg          string `json:"cfg" yaml:"cfg" mapstructure:"cfg"`
-	DisableTrace bool   `json:"disable_trace" yaml:"disable_trace" mapstructure:"disable_trace"` // 是否会禁用 Trace
-	DisableLog   bool   `json:"disable_log" yaml:"disable_log" mapstructure:"disable_log"`
+	Database     string    `json:"database" yaml:"database" mapstructure:"database"`
+	Address      string    `json:"address" yaml:"address" mapstructure:"address"`
+	Username     string    `json:"username" yaml:"username" mapstructure:"username"`
+	Password     string    `json:"password" yaml:"password" mapstructure:"password"`
+	EnableTLS    bool      `json:"enable_tls" yaml:"enable_tls" mapstructure:"enable_tls"` // 是否开启 tls，未配置 tls 时使用系统根证书校验服务端证书，自签证书需配置 tls.ca_file
+	Cfg          string    `json:"cfg" yaml:"cfg" mapstructure:"cfg"`
+	DisableTrace bool      `json:"disable_trace" yaml:"disable_trace" mapstructure:"disable_trace"` // 是否会禁用 Trace
+	DisableLog   bool      `json:"disable_log" yaml:"disable_log" mapstructure:"disable_log"`
+	TLS          TLSConfig `json:"tls" yaml:"tls" mapstructure:"tls"` // tls 证书配置，设置后开启 tls
 }
 
 type RedisConfig struct {
-	DB           int    `json:"db" yaml:"db" mapstructure:"db"`                                  // redis的哪个数据库
-	Addr         string `json:"addr" yaml:"addr" mapstructure:"addr"`                            // 服务器地址:端口
-	Username     string `json:"username" yaml:"username" mapstructure:"username"`                // 用户名
-	Password     string `json:"password" yaml:"password" mapstructure:"password"`                // 密码
-	EnableTLS    bool   `json:"enable_tls" yaml:"enable_tls" mapstructure:"enable_tls"`          // 是否开启 tls
-	IsCluster    bool   `json:"is_cluster" yaml:"is_cluster" mapstructure:"is_cluster"`          // 是否是集群模式
-	MasterOnly   bool   `json:"master_only" yaml:"master_only" mapstructure:"master_only"`       // 是否只读主库，仅在集群模式下生效
-	PoolSize     int    `json:"pool_size" yaml:"pool_size" mapstructure:"pool_size"`             // 连接池大小
-	DisableTrace bool   `json:"disable_trace" yaml:"disable_trace" mapstructure:"disable_trace"` // 是否会禁用 Trace
-	EnableLog    bool   `json:"enable_log" yaml:"enable_log" mapstructure:"enable_log"`
+	DB           int       `json:"db" yaml:"db" mapstructure:"db"`                                  // redis的哪个数据库
+	Addr         string    `json:"addr" yaml:"addr" mapstructure:"addr"`                            // 服务器地址:端口
+	Username     string    `json:"username" yaml:"username" mapstructure:"username"`                // 用户名
+	Password     string    `json:"password" yaml:"password" mapstructure:"password"`                // 密码
+	EnableTLS    bool      `json:"enable_tls" yaml:"enable_tls" mapstructure:"enable_tls"`          // 是否开启 tls
+	IsCluster    bool      `json:"is_cluster" yaml:"is_cluster" mapstructure:"is_cluster"`          // 是否是集群模式
+	MasterOnly   bool      `json:"master_only" yaml:"master_only" mapstructure:"master_only"`       // 是否只读主库，仅在集群模式下生效
+	PoolSize     int       `json:"pool_size" yaml:"pool_size" mapstructure:"pool_size"`             // 连接池大小
+	DisableTrace bool      `json:"disable_trace" yaml:"disable_trace" mapstructure:"disable_trace"` // 是否会禁用 Trace
+	EnableLog    bool      `json:"enable_log" yaml:"enable_log" mapstructure:"enable_log"`
+	TLS          TLSConfig `json:"tls" yaml:"tls" mapstructure:"tls"`                               // tls 证书配置，设置后开启 tls
+	DialTimeout  int       `json:"dial_timeout" yaml:"dial_timeout" mapstructure:"dial_timeout"`    // 建连超时，单位秒钟，为 0 时使用默认值 5 秒
+	ReadTimeout  int       `json:"read_timeout" yaml:"read_timeout" mapstructure:"read_timeout"`    // 读超时，单位秒钟，为 0 时使用默认值 3 秒，-1 表示不超时
+	WriteTimeout int       `json:"write_timeout" yaml:"write_timeout" mapstructure:"write_timeout"` // 写超时，单位秒钟，为 0 时与读超时相同，-1 表示不超时
+}
+
+// TLSConfig 描述 tls 证书配置，CertFile 和 KeyFile 需同时设置（双向认证）
+type TLSConfig struct {
+	CAFile             string `json:"ca_file" yaml:"ca_file" mapstructure:"ca_file"`                                        // 服务端 CA 证书路径，为空时使用系统 CA
+	CertFile           string `json:"cert_file" yaml:"cert_file" mapstructure:"cert_file"`                                  // 客户端证书路径
+	KeyFile            string `json:"key_file" yaml:"key_file" mapstructure:"key_file"`                                     // 客户端私钥路径
+	ServerName         string `json:"server_name" yaml:"server_name" mapstructure:"server_name"`                            // 校验的服务端名称
+	InsecureSkipVerify bool   `json:"insecure_skip_verify" yaml:"insecure_skip_verify" mapstructure:"insecure_skip_verify"` // 是否跳过服务端证书校验
 }
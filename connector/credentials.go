@@ -0,0 +1,42 @@
+package connector
+
+import (
+	"context"
+	"database/sql"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"gorm.io/driver/mysql"
+)
+
+// CredentialProvider returns the username and password to open a connection with, see WithCredentialProvider.
+// It's called for every new connection, so it should cache tokens that are valid for a while.
+type CredentialProvider func(ctx context.Context) (user, pass string, err error)
+
+// withMysqlCredentials makes cfg open its connections with the credentials of p rather than the ones
+// of its DSN, cfg is returned as-is when p is nil
+func withMysqlCredentials(cfg mysql.Config, p CredentialProvider) (mysql.Config, error) {
+	if p == nil {
+		return cfg, nil
+	}
+	driverCfg, err := mysqldriver.ParseDSN(cfg.DSN)
+	if err != nil {
+		return cfg, err
+	}
+	err = driverCfg.Apply(mysqldriver.BeforeConnect(func(ctx context.Context, c *mysqldriver.Config) error {
+		user, pass, err := p(ctx)
+		if err != nil {
+			return err
+		}
+		c.User, c.Passwd = user, pass
+		return nil
+	}))
+	if err != nil {
+		return cfg, err
+	}
+	connector, err := mysqldriver.NewConnector(driverCfg)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Conn = sql.OpenDB(connector)
+	return cfg, nil
+}
@@ -0,0 +1,51 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithCredentialProviderRedis(t *testing.T) {
+	s := miniredis.RunT(t)
+	s.RequireUserAuth("app", "token-1")
+
+	var calls atomic.Int32
+	provider := func(ctx context.Context) (string, string, error) {
+		calls.Add(1)
+		return "app", "token-1", nil
+	}
+	client, err := InitRedis(RedisConfig{Addr: s.Addr(), Password: "stale", PoolSize: 2, DisableTrace: true},
+		WithCredentialProvider(provider))
+	assert.NoError(t, err)
+	defer func() { _ = client.Close() }()
+	assert.Positive(t, calls.Load())
+	assert.NoError(t, client.Set(context.Background(), "k", "v", 0).Err())
+}
+
+func TestWithMysqlCredentials(t *testing.T) {
+	cfg := buildDsn("static", "secret", "127.0.0.1:1", "test", "timeout=100ms")
+	same, err := withMysqlCredentials(cfg, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, same.Conn)
+
+	failed := errors.New("token service down")
+	var calls atomic.Int32
+	cfg, err = withMysqlCredentials(cfg, func(ctx context.Context) (string, string, error) {
+		calls.Add(1)
+		return "", "", failed
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, cfg.Conn)
+
+	// the provider runs before every dial
+	type pinger interface {
+		PingContext(ctx context.Context) error
+	}
+	assert.ErrorIs(t, cfg.Conn.(pinger).PingContext(context.Background()), failed)
+	assert.Equal(t, int32(1), calls.Load())
+}
@@ -2,11 +2,15 @@ package connector
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
+	mysqldriver "github.com/go-sql-driver/mysql"
 	"github.com/mbeoliero/kit/log"
+	"github.com/mbeoliero/kit/utils/typex"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -14,15 +18,11 @@ import (
 	"gorm.io/plugin/opentelemetry/tracing"
 )
 
-func MustInitGorm(cfg MysqlConfig) *gorm.DB {
-	if cfg.MaxIdleConns == 0 {
-		cfg.MaxIdleConns = 3
-	}
-	if cfg.MaxOpenConns == 0 {
-		cfg.MaxOpenConns = 30
-	}
+func MustInitGorm(cfg MysqlConfig, opts ...Option) *gorm.DB {
+	cfg.MaxIdleConns = typex.Coalesce(cfg.MaxIdleConns, 3)
+	cfg.MaxOpenConns = typex.Coalesce(cfg.MaxOpenConns, 30)
 
-	db, err := InitGorm(cfg)
+	db, err := InitGorm(cfg, opts...)
 	if err != nil {
 		log.Error("MustInitGorm init db err %+v", err)
 		panic(err)
@@ -33,20 +33,25 @@ func MustInitGorm(cfg MysqlConfig) *gorm.DB {
 	return db
 }
 
-func InitGorm(m MysqlConfig) (*gorm.DB, error) {
-	if m.Dbname == "" {
-		return nil, errors.New("db name is empty, please check")
+func InitGorm(m MysqlConfig, opts ...Option) (*gorm.DB, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
 	}
 	log.Info("init gorm start: %+v", m)
+	o := newOptions(opts...)
 	var db *gorm.DB
+	var resolverPools sqlPools
 	var err error
+	if m.Config, err = registerMysqlTLS(m); err != nil {
+		return nil, err
+	}
 	if m.Path != "" {
-		db, err = singleMode(m)
+		db, err = singleMode(m, o.credentials)
 		if err != nil {
 			return nil, err
 		}
 	} else {
-		db, err = readWriteSplitMode(m)
+		db, resolverPools, err = readWriteSplitMode(m, o.credentials)
 		if err != nil {
 			return nil, err
 		}
@@ -56,23 +61,104 @@ func InitGorm(m MysqlConfig) (*gorm.DB, error) {
 	injectMysqlTracing(!m.DisableTrace, db)
 	log.Info("init grom inject mysql tracing done ")
 
-	sqlDB, _ := db.DB()
+	sqlDB, err := SqlDB(db)
+	if err != nil {
+		_ = resolverPools.Close()
+		return nil, err
+	}
+	pools := append(sqlPools{sqlDB}, resolverPools...)
+	if err = applyGormOptions(db, o); err != nil {
+		_ = pools.Close()
+		return nil, err
+	}
+
 	sqlDB.SetMaxIdleConns(m.MaxIdleConns)
 	sqlDB.SetMaxOpenConns(m.MaxOpenConns)
 	if m.ConnMaxLifetime > 0 {
 		sqlDB.SetConnMaxLifetime(time.Duration(m.ConnMaxLifetime) * time.Second)
 	}
+	if err = warmupSQL(sqlDB, o); err != nil {
+		_ = pools.Close()
+		return nil, err
+	}
+	Register("mysql:"+typex.Coalesce(m.Path, m.WritePath)+"/"+m.Dbname, pools)
 	log.Info("init gorm all done")
 	return db, nil
 }
 
-func readWriteSplitMode(m MysqlConfig) (*gorm.DB, error) {
+// applyGormOptions registers the plugins of WithGormPlugins then runs the callbacks of WithGormCallbacks on db
+func applyGormOptions(db *gorm.DB, o *initOptions) error {
+	for _, plugin := range o.gormPlugins {
+		if err := db.Use(plugin); err != nil {
+			log.Error("gorm register plugin %s err %+v", plugin.Name(), err)
+			return err
+		}
+	}
+	for _, fn := range o.gormCallbacks {
+		fn(db)
+	}
+	return nil
+}
+
+// ApplyPoolConfig re-applies MaxIdleConns, MaxOpenConns and ConnMaxLifetime of cfg on an
+// already opened db, so pool sizes can be tuned at runtime without a restart.
+// In read/write split mode the source and replica pools of dbresolver are updated as well.
+// The other fields of cfg are ignored.
+//
+// Redis has no equivalent: go-redis fixes PoolSize when the client is created,
+// so changing it requires building a new client.
+func ApplyPoolConfig(db *gorm.DB, cfg MysqlConfig) error {
+	sqlDB, err := SqlDB(db)
+	if err != nil {
+		return err
+	}
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	if cfg.ConnMaxLifetime > 0 {
+		sqlDB.SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetime) * time.Second)
+	}
+
+	if plugin, ok := db.Config.Plugins[(&dbresolver.DBResolver{}).Name()]; ok {
+		if resolver, ok := plugin.(*dbresolver.DBResolver); ok {
+			resolver.SetMaxIdleConns(cfg.MaxIdleConns).SetMaxOpenConns(cfg.MaxOpenConns)
+			if cfg.ConnMaxLifetime > 0 {
+				resolver.SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetime) * time.Second)
+			}
+		}
+	}
+
+	log.Info("apply gorm pool config done, max_idle_conns=%d max_open_conns=%d conn_max_lifetime=%ds",
+		cfg.MaxIdleConns, cfg.MaxOpenConns, cfg.ConnMaxLifetime)
+	return nil
+}
+
+// SqlDB returns the *sql.DB pool behind db, so database/sql code can share the pool with gorm.
+// In read/write split mode it's the pool of the first write path, dbresolver keeps its own pools.
+func SqlDB(db *gorm.DB) (*sql.DB, error) {
+	if db == nil {
+		return nil, errors.New("gorm db is nil")
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("get sql.DB from gorm: %w", err)
+	}
+	return sqlDB, nil
+}
+
+// readWriteSplitMode opens db on the first write path and registers dbresolver with a pool per write and
+// read path, the pools are returned so they can be closed with db
+func readWriteSplitMode(m MysqlConfig, credentials CredentialProvider) (db *gorm.DB, pools sqlPools, err error) {
 	writePath := strings.Split(m.WritePath, ",")
 	m.Path = writePath[0]
-	db, err := singleMode(m)
+	db, err = singleMode(m, credentials)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	defer func() {
+		if err != nil {
+			_ = pools.Close()
+		}
+	}()
 
 	cfg := dbresolver.Config{
 		Sources:           nil,
@@ -81,10 +167,18 @@ func readWriteSplitMode(m MysqlConfig) (*gorm.DB, error) {
 		TraceResolverMode: true,
 	}
 	for _, v := range writePath {
-		cfg.Sources = append(cfg.Sources, mysql.New(buildDsn(m.Username, m.Password, v, m.Dbname, m.Config)))
+		mysqlConfig, err := resolverPool(buildDsn(m.Username, m.Password, v, m.Dbname, m.Config), credentials, &pools)
+		if err != nil {
+			return nil, nil, err
+		}
+		cfg.Sources = append(cfg.Sources, mysql.New(mysqlConfig))
 	}
 	for _, v := range strings.Split(m.ReadPath, ",") {
-		cfg.Replicas = append(cfg.Replicas, mysql.New(buildDsn(m.Username, m.Password, v, m.Dbname, m.Config)))
+		mysqlConfig, err := resolverPool(buildDsn(m.Username, m.Password, v, m.Dbname, m.Config), credentials, &pools)
+		if err != nil {
+			return nil, nil, err
+		}
+		cfg.Replicas = append(cfg.Replicas, mysql.New(mysqlConfig))
 	}
 	log.Info("start to register db resolver %+v", cfg)
 
@@ -95,14 +189,36 @@ func readWriteSplitMode(m MysqlConfig) (*gorm.DB, error) {
 	err = db.Use(resolver)
 	if err != nil {
 		log.Error("gorm init db err %+v", err)
-		return nil, err
+		return nil, nil, err
 	}
-	return db, nil
+	return db, pools, nil
 }
 
-func singleMode(m MysqlConfig) (*gorm.DB, error) {
+// resolverPool opens the pool of a dbresolver source or replica and appends it to pools, dbresolver
+// doesn't expose the pools it opens itself
+func resolverPool(cfg mysql.Config, credentials CredentialProvider, pools *sqlPools) (mysql.Config, error) {
+	cfg, err := withMysqlCredentials(cfg, credentials)
+	if err != nil {
+		return cfg, err
+	}
+	if cfg.Conn == nil {
+		sqlDB, err := sql.Open("mysql", cfg.DSN)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Conn = sqlDB
+	}
+	*pools = append(*pools, cfg.Conn.(*sql.DB))
+	return cfg, nil
+}
+
+func singleMode(m MysqlConfig, credentials CredentialProvider) (*gorm.DB, error) {
 	mysqlConfig := buildDsn(m.Username, m.Password, m.Path, m.Dbname, m.Config)
 	log.Info("init gorm mysqlConfig: %+v", mysqlConfig)
+	mysqlConfig, err := withMysqlCredentials(mysqlConfig, credentials)
+	if err != nil {
+		return nil, err
+	}
 	db, err := gorm.Open(mysql.New(mysqlConfig))
 	if err != nil {
 		log.Error("gorm init db err %+v", err)
@@ -111,8 +227,29 @@ func singleMode(m MysqlConfig) (*gorm.DB, error) {
 	return db, nil
 }
 
+// registerMysqlTLS registers the tls config of m with the mysql driver and returns m.Config
+// with the tls parameter pointing to it, m.Config is returned as-is when tls isn't configured.
+func registerMysqlTLS(m MysqlConfig) (string, error) {
+	if m.TLS.IsZero() {
+		return m.Config, nil
+	}
+	tlsCfg, err := m.TLS.Build()
+	if err != nil {
+		return "", err
+	}
+	name := "kit-" + m.Dbname
+	if err = mysqldriver.RegisterTLSConfig(name, tlsCfg); err != nil {
+		return "", err
+	}
+
+	if m.Config == "" {
+		return "tls=" + name, nil
+	}
+	return m.Config + "&tls=" + name, nil
+}
+
 func buildDsn(username, password, path, dbname, config string) mysql.Config {
-	dsn := username + ":" + password + "@tcp(" + path + ")/" + dbname + "?" + config
+	dsn := mysqlDsn(username, password, path, dbname, config)
 	mysqlConfig := mysql.Config{
 		DSN:                       dsn,   // DSN data source name
 		DefaultStringSize:         191,   // string 类型字段的默认长度
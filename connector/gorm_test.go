@@ -0,0 +1,92 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type fakePlugin struct {
+	name string
+	err  error
+	db   *gorm.DB
+}
+
+func (p *fakePlugin) Name() string {
+	return p.name
+}
+
+func (p *fakePlugin) Initialize(db *gorm.DB) error {
+	p.db = db
+	return p.err
+}
+
+func TestApplyGormOptions(t *testing.T) {
+	db, f := newFakeGorm(t)
+
+	var order []string
+	plugin := &fakePlugin{name: "audit"}
+	o := newOptions(
+		WithGormPlugins(plugin),
+		WithGormCallbacks(func(db *gorm.DB) {
+			order = append(order, "callback")
+			_, ok := db.Config.Plugins["audit"]
+			assert.True(t, ok, "plugins are registered before the callbacks run")
+			assert.NoError(t, db.Callback().Raw().Before("gorm:raw").Register("test:raw", func(tx *gorm.DB) {
+				order = append(order, "raw")
+			}))
+		}),
+	)
+	assert.NoError(t, applyGormOptions(db, o))
+	assert.Same(t, db, plugin.db)
+	assert.Equal(t, []string{"callback"}, order)
+
+	assert.NoError(t, db.WithContext(context.Background()).Exec("UPDATE a").Error)
+	assert.Equal(t, []string{"callback", "raw"}, order)
+	assert.Equal(t, []string{"UPDATE a"}, f.Ops())
+}
+
+func TestApplyGormOptionsPluginError(t *testing.T) {
+	db, _ := newFakeGorm(t)
+
+	failed := errors.New("plugin failed")
+	called := false
+	o := newOptions(
+		WithGormPlugins(&fakePlugin{name: "broken", err: failed}),
+		WithGormCallbacks(func(*gorm.DB) { called = true }),
+	)
+	assert.ErrorIs(t, applyGormOptions(db, o), failed)
+	assert.False(t, called)
+}
+
+func TestSqlDB(t *testing.T) {
+	db, f := newFakeGorm(t)
+
+	sqlDB, err := SqlDB(db)
+	assert.NoError(t, err)
+	raw, err := db.DB()
+	assert.NoError(t, err)
+	assert.Same(t, raw, sqlDB)
+
+	// database/sql code shares the pool with gorm
+	_, err = sqlDB.Exec("DELETE a")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"DELETE a"}, f.Ops())
+
+	_, err = SqlDB(nil)
+	assert.Error(t, err)
+
+	// a transaction still exposes the pool it was started from
+	tx := db.Begin()
+	defer tx.Rollback()
+	txDB, err := SqlDB(tx)
+	assert.NoError(t, err)
+	assert.Same(t, sqlDB, txDB)
+
+	// a db without a pool
+	_, err = SqlDB(&gorm.DB{Config: &gorm.Config{}, Statement: &gorm.Statement{}})
+	assert.Error(t, err)
+}
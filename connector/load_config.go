@@ -0,0 +1,164 @@
+package connector
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/mbeoliero/kit/utils/typex"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadConfig decodes the yaml file at path into out, a pointer to a config such as *MysqlConfig or to a
+// struct grouping several of them. Keys are matched against the mapstructure tags, or the lowercased
+// field name when there's none, and every key matching no field is reported, so a typo like
+// max_idel_conns fails instead of being ignored. ${NAME} in a value is replaced with the environment
+// variable NAME, other $ are kept as-is since they're common in passwords.
+func LoadConfig(path string, out any) error {
+	rv := reflect.ValueOf(out)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("load config: out must be a non nil pointer, got %T", out)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("load config %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+	if err := decodeConfigNode(doc.Content[0], rv.Elem(), ""); err != nil {
+		return fmt.Errorf("load config %s: %w", path, err)
+	}
+	return nil
+}
+
+// decodeConfigNode decodes node into rv, key is the dotted path of node used in the errors
+func decodeConfigNode(node *yaml.Node, rv reflect.Value, key string) error {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Tag == "!!null" {
+		return nil
+	}
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+
+	switch {
+	case node.Kind == yaml.MappingNode && rv.Kind() == reflect.Struct:
+		var errs []error
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			name := node.Content[i].Value
+			field, ok := configField(rv, name)
+			if !ok {
+				errs = append(errs, fmt.Errorf("unknown key %q (line %d)", joinKey(key, name), node.Content[i].Line))
+				continue
+			}
+			errs = append(errs, decodeConfigNode(node.Content[i+1], field, joinKey(key, name)))
+		}
+		return errors.Join(errs...)
+	case node.Kind == yaml.MappingNode && rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String:
+		if rv.IsNil() {
+			rv.Set(reflect.MakeMap(rv.Type()))
+		}
+		var errs []error
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			name := node.Content[i].Value
+			elem := reflect.New(rv.Type().Elem()).Elem()
+			if err := decodeConfigNode(node.Content[i+1], elem, joinKey(key, name)); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			rv.SetMapIndex(reflect.ValueOf(name).Convert(rv.Type().Key()), elem)
+		}
+		return errors.Join(errs...)
+	case node.Kind == yaml.SequenceNode && rv.Kind() == reflect.Slice:
+		s := reflect.MakeSlice(rv.Type(), len(node.Content), len(node.Content))
+		var errs []error
+		for i, item := range node.Content {
+			errs = append(errs, decodeConfigNode(item, s.Index(i), fmt.Sprintf("%s[%d]", key, i)))
+		}
+		rv.Set(s)
+		return errors.Join(errs...)
+	case node.Kind == yaml.ScalarNode:
+		if err := typex.Decode(expandEnv(node.Value), rv.Addr().Interface()); err != nil {
+			return fmt.Errorf("invalid value for %q (line %d): %w", key, node.Line, err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid value for %q (line %d): can't decode a yaml %s into %s", key, node.Line, nodeKindName(node.Kind), rv.Type())
+	}
+}
+
+// configField returns the field of the struct rv the key name maps to
+func configField(rv reflect.Value, name string) (reflect.Value, bool) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag, _, _ := strings.Cut(sf.Tag.Get("mapstructure"), ",")
+		if tag == "-" {
+			continue
+		}
+		if tag == "" {
+			tag = strings.ToLower(sf.Name)
+		}
+		if tag == name {
+			return rv.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// expandEnv replaces ${NAME} with the value of the environment variable NAME
+func expandEnv(s string) string {
+	var sb strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(s[start:], '}')
+		if end < 0 {
+			break
+		}
+		sb.WriteString(s[:start])
+		sb.WriteString(os.Getenv(s[start+2 : start+end]))
+		s = s[start+end+1:]
+	}
+	if sb.Len() == 0 {
+		return s
+	}
+	sb.WriteString(s)
+	return sb.String()
+}
+
+func joinKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+func nodeKindName(kind yaml.Kind) string {
+	switch kind {
+	case yaml.MappingNode:
+		return "mapping"
+	case yaml.SequenceNode:
+		return "sequence"
+	default:
+		return "scalar"
+	}
+}
@@ -0,0 +1,61 @@
+package connector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "s3cret")
+	path := writeConfig(t, `
+mysql:
+  path: 127.0.0.1:3306
+  dbname: app
+  password: ${DB_PASSWORD}$x
+  max_idle_conns: 5
+  tls:
+    server_name: db.internal
+redis:
+  - addr: 127.0.0.1:6379
+    pool_size: 20
+`)
+
+	var cfg struct {
+		Mysql MysqlConfig   `mapstructure:"mysql"`
+		Redis []RedisConfig `mapstructure:"redis"`
+	}
+	assert.NoError(t, LoadConfig(path, &cfg))
+	assert.Equal(t, MysqlConfig{
+		Path:         "127.0.0.1:3306",
+		Dbname:       "app",
+		Password:     "s3cret$x",
+		MaxIdleConns: 5,
+		TLS:          TLSConfig{ServerName: "db.internal"},
+	}, cfg.Mysql)
+	assert.Equal(t, []RedisConfig{{Addr: "127.0.0.1:6379", PoolSize: 20}}, cfg.Redis)
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	var cfg MysqlConfig
+	err := LoadConfig(writeConfig(t, "path: a\nmax_idel_conns: 5\ntls:\n  ca: x\n"), &cfg)
+	assert.ErrorContains(t, err, `unknown key "max_idel_conns" (line 2)`)
+	assert.ErrorContains(t, err, `unknown key "tls.ca" (line 4)`)
+
+	err = LoadConfig(writeConfig(t, "max_open_conns: many\n"), &cfg)
+	assert.ErrorContains(t, err, `invalid value for "max_open_conns" (line 1)`)
+
+	err = LoadConfig(writeConfig(t, "tls: [a]\n"), &cfg)
+	assert.ErrorContains(t, err, "can't decode a yaml sequence")
+
+	assert.Error(t, LoadConfig(filepath.Join(t.TempDir(), "none.yaml"), &cfg))
+	assert.Error(t, LoadConfig(writeConfig(t, "path: a\n"), cfg))
+}
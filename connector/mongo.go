@@ -2,6 +2,7 @@ package connector
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"os"
@@ -30,6 +31,9 @@ func MustInitMongo(cfg MongoConfig) (*mongo.Client, *mongo.Database) {
 }
 
 func InitMongo(mgoCfg MongoConfig) (*mongo.Client, error) {
+	if err := mgoCfg.Validate(); err != nil {
+		return nil, err
+	}
 	url := fmt.Sprintf("mongodb://%s:%s@%s", mgoCfg.Username, mgoCfg.Password, mgoCfg.Address)
 	if os.Getenv("") == "" && strings.Contains(url, "localhost") && mgoCfg.Password == "" {
 		url = fmt.Sprintf("mongodb://%s", mgoCfg.Address)
@@ -39,6 +43,14 @@ func InitMongo(mgoCfg MongoConfig) (*mongo.Client, error) {
 	}
 
 	opt := options.Client()
+	// enable_tls alone verifies the server against the system roots, unlike redis which skips the verification
+	tlsCfg, err := buildTLS(mgoCfg.TLS, mgoCfg.EnableTLS, &tls.Config{})
+	if err != nil {
+		return nil, err
+	}
+	if tlsCfg != nil {
+		opt.SetTLSConfig(tlsCfg)
+	}
 	injectMongoTracing(!mgoCfg.DisableTrace, mgoCfg.DisableLog, opt)
 
 	log.Info("init mongo idle time= %d cfg=%+v", 10*time.Second, mgoCfg)
@@ -51,6 +63,7 @@ func InitMongo(mgoCfg MongoConfig) (*mongo.Client, error) {
 	if err != nil {
 		return nil, err
 	}
+	Register("mongo:"+mgoCfg.Address, mongoCloser{cli: cli})
 	log.Info("init mongo done")
 	return cli, err
 }
@@ -0,0 +1,81 @@
+package connector
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mbeoliero/kit/repox"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// Collection is a typed wrapper of a mongo collection, documents are marshaled from and
+// unmarshaled into T with the bson codec. Every call runs with the given ctx, so its
+// deadline and cancellation are honored by the driver.
+type Collection[T any] struct {
+	Coll *mongo.Collection
+}
+
+// NewCollection wraps the collection name of db, e.g. the database returned by MustInitMongo.
+func NewCollection[T any](db *mongo.Database, name string) *Collection[T] {
+	return &Collection[T]{Coll: db.Collection(name)}
+}
+
+// InsertOne inserts doc and returns its _id, generated by the driver when doc has none.
+func (c *Collection[T]) InsertOne(ctx context.Context, doc *T) (any, error) {
+	res, err := c.Coll.InsertOne(ctx, doc)
+	if err != nil {
+		return nil, err
+	}
+	return res.InsertedID, nil
+}
+
+// FindByID returns the document with _id id, or repox.DataNotFound when there is none.
+func (c *Collection[T]) FindByID(ctx context.Context, id any) (*T, error) {
+	var doc T
+	err := c.Coll.FindOne(ctx, bson.M{"_id": id}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, repox.DataNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// FindMany returns the documents matching filter, a nil filter matches all documents.
+func (c *Collection[T]) FindMany(ctx context.Context, filter any) ([]*T, error) {
+	if filter == nil {
+		filter = bson.M{}
+	}
+	cursor, err := c.Coll.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = cursor.Close(ctx) }()
+
+	var docs []*T
+	if err = cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// UpdateByID applies update, an update document such as bson.M{"$set": ...}, to the document
+// with _id id and returns whether a document matched.
+func (c *Collection[T]) UpdateByID(ctx context.Context, id any, update any) (bool, error) {
+	res, err := c.Coll.UpdateByID(ctx, id, update)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+// DeleteByID deletes the document with _id id and returns whether it existed.
+func (c *Collection[T]) DeleteByID(ctx context.Context, id any) (bool, error) {
+	res, err := c.Coll.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return false, err
+	}
+	return res.DeletedCount > 0, nil
+}
@@ -0,0 +1,125 @@
+package connector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mbeoliero/kit/repox"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/x/mongo/driver/drivertest"
+	"go.mongodb.org/mongo-driver/v2/x/mongo/driver/xoptions"
+)
+
+type testUser struct {
+	ID   bson.ObjectID `bson:"_id,omitempty"`
+	Name string        `bson:"name"`
+}
+
+// newMockCollection returns a Collection on a mock deployment replying the responses queued with AddResponses
+func newMockCollection(t *testing.T) (*Collection[testUser], *drivertest.MockDeployment) {
+	t.Helper()
+	md := drivertest.NewMockDeployment()
+	opts := options.Client()
+	assert.NoError(t, xoptions.SetInternalClientOptions(opts, "deployment", md))
+	client, err := mongo.Connect(opts)
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = client.Disconnect(context.Background()) })
+	return NewCollection[testUser](client.Database("test"), "users"), md
+}
+
+func cursorResponse(docs ...any) bson.D {
+	batch := append(bson.A{}, docs...)
+	return bson.D{
+		{Key: "ok", Value: 1},
+		{Key: "cursor", Value: bson.D{
+			{Key: "id", Value: int64(0)},
+			{Key: "ns", Value: "test.users"},
+			{Key: "firstBatch", Value: batch},
+		}},
+	}
+}
+
+func TestCollection_InsertOne(t *testing.T) {
+	c, md := newMockCollection(t)
+	ctx := context.Background()
+
+	md.AddResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}})
+	id, err := c.InsertOne(ctx, &testUser{Name: "a"})
+	assert.NoError(t, err)
+	oid, ok := id.(bson.ObjectID)
+	assert.True(t, ok)
+	assert.False(t, oid.IsZero())
+
+	md.AddResponses(bson.D{{Key: "ok", Value: 0}, {Key: "code", Value: 11000}, {Key: "errmsg", Value: "duplicate key"}})
+	_, err = c.InsertOne(ctx, &testUser{ID: oid, Name: "a"})
+	assert.Error(t, err)
+}
+
+func TestCollection_FindByID(t *testing.T) {
+	c, md := newMockCollection(t)
+	ctx := context.Background()
+	oid := bson.NewObjectID()
+
+	md.AddResponses(cursorResponse(bson.D{{Key: "_id", Value: oid}, {Key: "name", Value: "a"}}))
+	user, err := c.FindByID(ctx, oid)
+	assert.NoError(t, err)
+	assert.Equal(t, &testUser{ID: oid, Name: "a"}, user)
+
+	md.AddResponses(cursorResponse())
+	user, err = c.FindByID(ctx, bson.NewObjectID())
+	assert.ErrorIs(t, err, repox.DataNotFound)
+	assert.Nil(t, user)
+}
+
+func TestCollection_FindMany(t *testing.T) {
+	c, md := newMockCollection(t)
+	ctx := context.Background()
+	a, b := bson.NewObjectID(), bson.NewObjectID()
+
+	md.AddResponses(cursorResponse(
+		bson.D{{Key: "_id", Value: a}, {Key: "name", Value: "a"}},
+		bson.D{{Key: "_id", Value: b}, {Key: "name", Value: "b"}},
+	))
+	users, err := c.FindMany(ctx, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []*testUser{{ID: a, Name: "a"}, {ID: b, Name: "b"}}, users)
+
+	md.AddResponses(cursorResponse())
+	users, err = c.FindMany(ctx, bson.M{"name": "c"})
+	assert.NoError(t, err)
+	assert.Empty(t, users)
+}
+
+func TestCollection_UpdateByID(t *testing.T) {
+	c, md := newMockCollection(t)
+	ctx := context.Background()
+	update := bson.M{"$set": bson.M{"name": "b"}}
+
+	md.AddResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+	matched, err := c.UpdateByID(ctx, bson.NewObjectID(), update)
+	assert.NoError(t, err)
+	assert.True(t, matched)
+
+	md.AddResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}, {Key: "nModified", Value: 0}})
+	matched, err = c.UpdateByID(ctx, bson.NewObjectID(), update)
+	assert.NoError(t, err)
+	assert.False(t, matched)
+}
+
+func TestCollection_DeleteByID(t *testing.T) {
+	c, md := newMockCollection(t)
+	ctx := context.Background()
+
+	md.AddResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}})
+	deleted, err := c.DeleteByID(ctx, bson.NewObjectID())
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+
+	md.AddResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}})
+	deleted, err = c.DeleteByID(ctx, bson.NewObjectID())
+	assert.NoError(t, err)
+	assert.False(t, deleted)
+}
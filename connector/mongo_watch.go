@@ -0,0 +1,142 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mbeoliero/kit/log"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// watchRetryBackoff is the delay before a change stream interrupted by a transient error is reopened
+var watchRetryBackoff = time.Second
+
+// ChangeEvent is an event of the change stream opened by Watch. FullDocument is the document after
+// the change, nil for deletes. The last event of a stream that failed only carries Err.
+type ChangeEvent[T any] struct {
+	OperationType string   `bson:"operationType"` // insert, update, replace, delete...
+	DocumentKey   bson.Raw `bson:"documentKey"`
+	FullDocument  *T       `bson:"fullDocument"`
+	ResumeToken   bson.Raw `bson:"-"`
+	Err           error    `bson:"-"`
+}
+
+// Watch opens a change stream on coll filtered by pipeline (nil watches every change) and sends
+// its events, with fullDocument decoded into T, e.g.
+//
+//	events, cancel, err := connector.Watch[User](ctx, users.Coll, nil)
+//
+// Updates are looked up so FullDocument holds the whole document. On transient errors (network,
+// resumable change stream errors) the stream is reopened from the last resume token. The channel is
+// closed when ctx is done, cancel is called or the stream fails, in which case the last event carries Err.
+func Watch[T any](ctx context.Context, coll *mongo.Collection, pipeline any) (<-chan ChangeEvent[T], func(), error) {
+	if pipeline == nil {
+		pipeline = mongo.Pipeline{}
+	}
+	return watchChanges[T](ctx, coll.Name(), func(ctx context.Context, token bson.Raw) (changeStream, error) {
+		return openChangeStream(ctx, coll, pipeline, token)
+	})
+}
+
+// changeStream is the part of *mongo.ChangeStream Watch uses
+type changeStream interface {
+	Next(ctx context.Context) bool
+	Decode(val any) error
+	ResumeToken() bson.Raw
+	Err() error
+	Close(ctx context.Context) error
+}
+
+// watchChanges runs Watch on the streams returned by open, which resumes after token when it's not nil
+func watchChanges[T any](ctx context.Context, name string, open func(ctx context.Context, token bson.Raw) (changeStream, error)) (<-chan ChangeEvent[T], func(), error) {
+	ctx, cancel := context.WithCancel(ctx)
+	cs, err := open(ctx, nil)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+
+	events := make(chan ChangeEvent[T])
+	go func() {
+		defer close(events)
+		for {
+			err := forwardChanges(ctx, cs, events)
+			token := cs.ResumeToken()
+			_ = cs.Close(context.Background())
+			if ctx.Err() != nil || err == nil {
+				// err is nil when the server ended the stream, e.g. after an invalidate event
+				return
+			}
+			if !isTransientMongoError(err) {
+				sendChange(ctx, events, ChangeEvent[T]{Err: err})
+				return
+			}
+
+			log.CtxWarn(ctx, "mongo change stream on %s interrupted, resuming: %v", name, err)
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(watchRetryBackoff):
+				}
+				if cs, err = open(ctx, token); err == nil {
+					break
+				}
+				if !isTransientMongoError(err) {
+					sendChange(ctx, events, ChangeEvent[T]{Err: err})
+					return
+				}
+			}
+		}
+	}()
+	return events, cancel, nil
+}
+
+// openChangeStream opens a change stream resuming after token when it's not nil
+func openChangeStream(ctx context.Context, coll *mongo.Collection, pipeline any, token bson.Raw) (*mongo.ChangeStream, error) {
+	opts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+	if token != nil {
+		opts.SetResumeAfter(token)
+	}
+	return coll.Watch(ctx, pipeline, opts)
+}
+
+// forwardChanges sends the events of cs until it stops and returns the error that stopped it
+func forwardChanges[T any](ctx context.Context, cs changeStream, events chan<- ChangeEvent[T]) error {
+	for cs.Next(ctx) {
+		var ev ChangeEvent[T]
+		if err := cs.Decode(&ev); err != nil {
+			return err
+		}
+		ev.ResumeToken = cs.ResumeToken()
+		if !sendChange(ctx, events, ev) {
+			return ctx.Err()
+		}
+	}
+	return cs.Err()
+}
+
+// sendChange sends ev unless ctx is done first
+func sendChange[T any](ctx context.Context, events chan<- ChangeEvent[T], ev ChangeEvent[T]) bool {
+	select {
+	case events <- ev:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// isTransientMongoError reports whether a change stream stopped by err can be resumed
+func isTransientMongoError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if mongo.IsNetworkError(err) {
+		return true
+	}
+	var le mongo.LabeledError
+	return errors.As(err, &le) && le.HasErrorLabel("ResumableChangeStreamError")
+}
@@ -0,0 +1,113 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// fakeChangeStream replays docs then stops with err
+type fakeChangeStream struct {
+	docs  []bson.Raw
+	err   error
+	cur   bson.Raw
+	token bson.Raw
+}
+
+func (f *fakeChangeStream) Next(ctx context.Context) bool {
+	if len(f.docs) == 0 {
+		return false
+	}
+	f.cur, f.docs = f.docs[0], f.docs[1:]
+	f.token = f.cur.Lookup("_id").Document()
+	return true
+}
+
+func (f *fakeChangeStream) Decode(val any) error        { return bson.Unmarshal(f.cur, val) }
+func (f *fakeChangeStream) ResumeToken() bson.Raw       { return f.token }
+func (f *fakeChangeStream) Err() error                  { return f.err }
+func (f *fakeChangeStream) Close(context.Context) error { return nil }
+
+type watchedDoc struct {
+	Name string `bson:"name"`
+}
+
+func changeDoc(t *testing.T, seq int, name string) bson.Raw {
+	b, err := bson.Marshal(bson.D{
+		{Key: "_id", Value: bson.D{{Key: "_data", Value: fmt.Sprint(seq)}}},
+		{Key: "operationType", Value: "insert"},
+		{Key: "fullDocument", Value: bson.D{{Key: "name", Value: name}}},
+	})
+	assert.NoError(t, err)
+	return b
+}
+
+func TestIsTransientMongoError(t *testing.T) {
+	assert.False(t, isTransientMongoError(nil))
+	assert.False(t, isTransientMongoError(context.Canceled))
+	assert.False(t, isTransientMongoError(fmt.Errorf("watch: %w", context.DeadlineExceeded)))
+	assert.False(t, isTransientMongoError(errors.New("boom")))
+	assert.False(t, isTransientMongoError(mongo.CommandError{Code: 13, Message: "unauthorized"}))
+
+	assert.True(t, isTransientMongoError(mongo.CommandError{Labels: []string{"NetworkError"}}))
+	assert.True(t, isTransientMongoError(fmt.Errorf("next: %w", mongo.CommandError{Labels: []string{"ResumableChangeStreamError"}})))
+}
+
+func TestWatchChangesResume(t *testing.T) {
+	prevBackoff := watchRetryBackoff
+	watchRetryBackoff = time.Millisecond
+	defer func() { watchRetryBackoff = prevBackoff }()
+
+	resumable := mongo.CommandError{Labels: []string{"ResumableChangeStreamError"}}
+	fatal := mongo.CommandError{Code: 13, Message: "unauthorized"}
+	var tokens []bson.Raw
+	streams := []changeStream{
+		&fakeChangeStream{docs: []bson.Raw{changeDoc(t, 1, "a"), changeDoc(t, 2, "b")}, err: resumable},
+		&fakeChangeStream{docs: []bson.Raw{changeDoc(t, 3, "c")}, err: fatal},
+	}
+	open := func(ctx context.Context, token bson.Raw) (changeStream, error) {
+		tokens = append(tokens, token)
+		if len(tokens) == 2 {
+			// a transient failure to reopen is retried
+			return nil, mongo.CommandError{Labels: []string{"NetworkError"}}
+		}
+		cs := streams[0]
+		streams = streams[1:]
+		return cs, nil
+	}
+
+	events, cancel, err := watchChanges[watchedDoc](context.Background(), "users", open)
+	assert.NoError(t, err)
+	defer cancel()
+
+	var names []string
+	var last ChangeEvent[watchedDoc]
+	for ev := range events {
+		if ev.Err != nil {
+			last = ev
+			continue
+		}
+		assert.Equal(t, "insert", ev.OperationType)
+		names = append(names, ev.FullDocument.Name)
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, names)
+	assert.Equal(t, fatal, last.Err)
+
+	// the stream is reopened after the last event sent, the first open has no token
+	token := changeDoc(t, 2, "b").Lookup("_id").Document()
+	assert.Equal(t, []bson.Raw{nil, token, token}, tokens)
+}
+
+func TestWatchChangesOpenError(t *testing.T) {
+	failed := errors.New("not a replica set")
+	_, _, err := watchChanges[watchedDoc](context.Background(), "users", func(context.Context, bson.Raw) (changeStream, error) {
+		return nil, failed
+	})
+	assert.ErrorIs(t, err, failed)
+}
@@ -0,0 +1,69 @@
+package connector
+
+import "gorm.io/gorm"
+
+// Option configures the Init* constructors, each option documents the constructors it applies to.
+type Option func(*initOptions)
+
+type initOptions struct {
+	gormPlugins   []gorm.Plugin
+	gormCallbacks []func(*gorm.DB)
+
+	warmup       int
+	warmupStrict bool
+
+	credentials CredentialProvider
+}
+
+func newOptions(opts ...Option) *initOptions {
+	o := &initOptions{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+	return o
+}
+
+// WithGormPlugins registers plugins on the db once it's opened, a failing registration
+// aborts the construction. Applies to InitGorm and MustInitGorm.
+func WithGormPlugins(plugins ...gorm.Plugin) Option {
+	return func(o *initOptions) {
+		o.gormPlugins = append(o.gormPlugins, plugins...)
+	}
+}
+
+// WithGormCallbacks runs fn on the db once it's opened and its plugins are registered,
+// e.g. to register gorm callbacks. Applies to InitGorm and MustInitGorm.
+func WithGormCallbacks(fn ...func(*gorm.DB)) Option {
+	return func(o *initOptions) {
+		o.gormCallbacks = append(o.gormCallbacks, fn...)
+	}
+}
+
+// WithWarmup opens n connections of the pool at construction, so the first requests after a deploy
+// don't pay the connection setup. Warmup failures are logged and ignored unless WithWarmupStrict is set.
+// Applies to InitGorm, where n is capped by MaxIdleConns, and InitRedis/InitClusterRedis, where every
+// master of a cluster gets n connections, as well as their Must variants.
+func WithWarmup(n int) Option {
+	return func(o *initOptions) {
+		o.warmup = n
+	}
+}
+
+// WithWarmupStrict makes a failing WithWarmup abort the construction.
+func WithWarmupStrict() Option {
+	return func(o *initOptions) {
+		o.warmupStrict = true
+	}
+}
+
+// WithCredentialProvider fetches the username and password through p whenever a connection is opened,
+// reconnections included, instead of using the static ones of the config, so rotating credentials
+// such as AWS IAM auth tokens are picked up without a restart. Applies to InitGorm, InitRedis and
+// InitClusterRedis as well as their Must variants.
+func WithCredentialProvider(p CredentialProvider) Option {
+	return func(o *initOptions) {
+		o.credentials = p
+	}
+}
@@ -0,0 +1,104 @@
+package connector
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+const (
+	labelOperation = "operation"
+	labelTable     = "table"
+
+	queryMetricsStartKey = "kit:query_metrics_start"
+)
+
+var dbQueryHistogram = promauto.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "db_query_latency_ms",
+		Help:    "Latency (milliseconds) of gorm queries by operation and table.",
+		Buckets: buckets,
+	},
+	[]string{labelDb, labelOperation, labelTable, labelSuccess},
+)
+
+// QueryRecorder records the latency of the gorm queries collected by WithQueryMetrics.
+// operation is one of select, insert, update, delete, row and raw, table is empty for raw sql
+type QueryRecorder interface {
+	ObserveQuery(operation, table string, d time.Duration, err error)
+}
+
+// prometheusQueryRecorder is the default QueryRecorder, the db_query_latency_ms histogram
+type prometheusQueryRecorder struct{}
+
+func (prometheusQueryRecorder) ObserveQuery(operation, table string, d time.Duration, err error) {
+	success := "true"
+	if err != nil {
+		success = "false"
+	}
+	dbQueryHistogram.WithLabelValues(mysqlDb, operation, table, success).Observe(float64(d.Milliseconds()))
+}
+
+// WithQueryMetrics records the latency of every gorm query, labelled by operation and table,
+// with r, nil records to the db_query_latency_ms Prometheus histogram. gorm.ErrRecordNotFound
+// doesn't count as a failure. Applies to InitGorm and MustInitGorm.
+func WithQueryMetrics(r QueryRecorder) Option {
+	if r == nil {
+		r = prometheusQueryRecorder{}
+	}
+	return WithGormPlugins(&queryMetricsPlugin{recorder: r})
+}
+
+// queryMetricsPlugin times the gorm callbacks of every operation
+type queryMetricsPlugin struct {
+	recorder QueryRecorder
+}
+
+func (p *queryMetricsPlugin) Name() string {
+	return "kit:query_metrics"
+}
+
+func (p *queryMetricsPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	before, after := p.Name()+":before_", p.Name()+":after_"
+	return errors.Join(
+		cb.Query().Before("*").Register(before+"select", p.before),
+		cb.Query().After("*").Register(after+"select", p.after("select")),
+		cb.Create().Before("*").Register(before+"insert", p.before),
+		cb.Create().After("*").Register(after+"insert", p.after("insert")),
+		cb.Update().Before("*").Register(before+"update", p.before),
+		cb.Update().After("*").Register(after+"update", p.after("update")),
+		cb.Delete().Before("*").Register(before+"delete", p.before),
+		cb.Delete().After("*").Register(after+"delete", p.after("delete")),
+		cb.Row().Before("*").Register(before+"row", p.before),
+		cb.Row().After("*").Register(after+"row", p.after("row")),
+		cb.Raw().Before("*").Register(before+"raw", p.before),
+		cb.Raw().After("*").Register(after+"raw", p.after("raw")),
+	)
+}
+
+func (p *queryMetricsPlugin) before(db *gorm.DB) {
+	db.InstanceSet(queryMetricsStartKey, time.Now())
+}
+
+func (p *queryMetricsPlugin) after(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		v, ok := db.InstanceGet(queryMetricsStartKey)
+		if !ok {
+			return
+		}
+		start, ok := v.(time.Time)
+		if !ok {
+			return
+		}
+
+		err := db.Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = nil
+		}
+		p.recorder.ObserveQuery(operation, db.Statement.Table, time.Since(start), err)
+	}
+}
@@ -0,0 +1,58 @@
+package connector
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+type recordedQuery struct {
+	operation string
+	table     string
+}
+
+type queryRecorderFunc func(operation, table string, d time.Duration, err error)
+
+func (f queryRecorderFunc) ObserveQuery(operation, table string, d time.Duration, err error) {
+	f(operation, table, d, err)
+}
+
+type metricsUser struct {
+	ID   int64
+	Name string
+}
+
+func TestWithQueryMetrics(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		queries []recordedQuery
+	)
+	recorder := queryRecorderFunc(func(operation, table string, d time.Duration, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		queries = append(queries, recordedQuery{operation: operation, table: table})
+	})
+
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: "user:pass@tcp(127.0.0.1:3306)/test", SkipInitializeWithVersion: true}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	assert.NoError(t, err)
+	for _, plugin := range newOptions(WithQueryMetrics(recorder)).gormPlugins {
+		assert.NoError(t, db.Use(plugin))
+	}
+
+	db.Create(&metricsUser{Name: "bob"})
+	db.Find(&[]metricsUser{})
+	db.Model(&metricsUser{ID: 1}).Update("name", "alice")
+	db.Delete(&metricsUser{ID: 1})
+
+	assert.Equal(t, []recordedQuery{
+		{operation: "insert", table: "metrics_users"},
+		{operation: "select", table: "metrics_users"},
+		{operation: "update", table: "metrics_users"},
+		{operation: "delete", table: "metrics_users"},
+	}, queries)
+}
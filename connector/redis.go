@@ -3,28 +3,29 @@ package connector
 import (
 	"context"
 	"crypto/tls"
+	"fmt"
 	"net"
 	"strings"
 	"time"
 
 	"github.com/mbeoliero/kit/log"
+	"github.com/mbeoliero/kit/redisx"
+	"github.com/mbeoliero/kit/utils/typex"
 	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 )
 
-func MustInitRedis(cfg RedisConfig) redis.UniversalClient {
-	if cfg.PoolSize == 0 {
-		cfg.PoolSize = 1000
-	}
+func MustInitRedis(cfg RedisConfig, opts ...Option) redis.UniversalClient {
+	cfg.PoolSize = typex.Coalesce(cfg.PoolSize, 1000)
 
 	if cfg.IsCluster {
-		return MustInitClusterRedis(cfg)
+		return MustInitClusterRedis(cfg, opts...)
 	}
-	return MustInitDefaultRedis(cfg)
+	return MustInitDefaultRedis(cfg, opts...)
 }
 
-func MustInitDefaultRedis(redisCfg RedisConfig) *redis.Client {
-	client, err := InitRedis(redisCfg)
+func MustInitDefaultRedis(redisCfg RedisConfig, opts ...Option) *redis.Client {
+	client, err := InitRedis(redisCfg, opts...)
 	if err != nil {
 		log.Error("init redis failed with error %v, cfg %+v", err, redisCfg)
 		panic(err)
@@ -38,8 +39,8 @@ func MustInitDefaultRedis(redisCfg RedisConfig) *redis.Client {
 	return client
 }
 
-func MustInitClusterRedis(redisCfg RedisConfig) *redis.ClusterClient {
-	client, err := InitClusterRedis(redisCfg)
+func MustInitClusterRedis(redisCfg RedisConfig, opts ...Option) *redis.ClusterClient {
+	client, err := InitClusterRedis(redisCfg, opts...)
 	if err != nil {
 		log.Error("init redis failed with error %v, cfg %+v", err, redisCfg)
 		panic(err)
@@ -53,17 +54,27 @@ func MustInitClusterRedis(redisCfg RedisConfig) *redis.ClusterClient {
 	return client
 }
 
-func InitRedis(redisCfg RedisConfig) (client *redis.Client, err error) {
+func InitRedis(redisCfg RedisConfig, opts ...Option) (client *redis.Client, err error) {
+	if err = redisCfg.Validate(); err != nil {
+		return nil, err
+	}
 	log.Info("init redis cfg=%+v", redisCfg)
+	o := newOptions(opts...)
 	options := &redis.Options{
 		Addr:     redisCfg.Addr,
 		Username: redisCfg.Username,
 		Password: redisCfg.Password, // no password set
 		DB:       redisCfg.DB,       // use default DB
 		PoolSize: redisCfg.PoolSize,
+
+		DialTimeout:  redisDialTimeout(redisCfg.DialTimeout),
+		ReadTimeout:  redisTimeout(redisCfg.ReadTimeout),
+		WriteTimeout: redisTimeout(redisCfg.WriteTimeout),
+
+		CredentialsProviderContext: o.credentials,
 	}
-	if redisCfg.EnableTLS {
-		options.TLSConfig = &tls.Config{InsecureSkipVerify: true}
+	if options.TLSConfig, err = buildTLS(redisCfg.TLS, redisCfg.EnableTLS, &tls.Config{InsecureSkipVerify: true}); err != nil {
+		return nil, err
 	}
 	// 国内(腾讯)不支持3的协议，所以使用2的协议
 	//if idc.IsCN() {
@@ -81,23 +92,38 @@ func InitRedis(redisCfg RedisConfig) (client *redis.Client, err error) {
 	if err != nil {
 		return nil, err
 	}
+	if err = warmupRedis(client, o); err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+	Register(fmt.Sprintf("redis:%s/%d", redisCfg.Addr, redisCfg.DB), client)
 	return client, nil
 }
 
-func InitClusterRedis(redisCfg RedisConfig) (client *redis.ClusterClient, err error) {
+func InitClusterRedis(redisCfg RedisConfig, opts ...Option) (client *redis.ClusterClient, err error) {
+	if err = redisCfg.Validate(); err != nil {
+		return nil, err
+	}
 	log.Info("init cluster redis cfg=%+v", redisCfg)
+	o := newOptions(opts...)
 	options := &redis.ClusterOptions{
 		Addrs:    []string{redisCfg.Addr},
 		Username: redisCfg.Username,
 		Password: redisCfg.Password, // no password set
 		PoolSize: redisCfg.PoolSize,
+
+		DialTimeout:  redisDialTimeout(redisCfg.DialTimeout),
+		ReadTimeout:  redisTimeout(redisCfg.ReadTimeout),
+		WriteTimeout: redisTimeout(redisCfg.WriteTimeout),
+
+		CredentialsProviderContext: o.credentials,
 	}
 	// 国内(腾讯)不支持3的协议，所以使用2的协议
 	//if idc.IsCN() {
 	//	options.Protocol = 2
 	//}
-	if redisCfg.EnableTLS {
-		options.TLSConfig = &tls.Config{InsecureSkipVerify: true}
+	if options.TLSConfig, err = buildTLS(redisCfg.TLS, redisCfg.EnableTLS, &tls.Config{InsecureSkipVerify: true}); err != nil {
+		return nil, err
 	}
 	if !redisCfg.MasterOnly {
 		options.ReadOnly = true
@@ -118,13 +144,40 @@ func InitClusterRedis(redisCfg RedisConfig) (client *redis.ClusterClient, err er
 	if err != nil {
 		return nil, err
 	}
+	if err = warmupRedis(client, o); err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+	Register("redis:"+redisCfg.Addr, client)
 	return client, nil
 }
 
+// redisTimeout converts the read or write timeout of RedisConfig in seconds, 0 keeps the go-redis
+// default and -1 (no timeout) is passed as-is
+func redisTimeout(seconds int) time.Duration {
+	if seconds < 0 {
+		return -1
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// redisDialTimeout converts the dial timeout of RedisConfig in seconds. Dialing always has a timeout,
+// a negative one keeps the go-redis default like 0, Validate rejects it
+func redisDialTimeout(seconds int) time.Duration {
+	if seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
 func injectRedisTracing(enableTracing bool, enableLog bool, client redis.UniversalClient) error {
 	if enableTracing {
 		client.AddHook(RedisHook{enableLog: enableLog})
-		return redisotel.InstrumentTracing(client)
+		if err := redisotel.InstrumentTracing(client); err != nil {
+			return err
+		}
+		// added after the otel hook so redisx operation names can rename its spans
+		client.AddHook(redisx.SpanNameHook{})
 	}
 	return nil
 }
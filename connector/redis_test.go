@@ -0,0 +1,18 @@
+package connector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisTimeout(t *testing.T) {
+	assert.Equal(t, time.Duration(0), redisTimeout(0))
+	assert.Equal(t, 2*time.Second, redisTimeout(2))
+	assert.Equal(t, time.Duration(-1), redisTimeout(-1))
+
+	assert.Equal(t, time.Duration(0), redisDialTimeout(0))
+	assert.Equal(t, 2*time.Second, redisDialTimeout(2))
+	assert.Equal(t, time.Duration(0), redisDialTimeout(-1))
+}
@@ -0,0 +1,58 @@
+package connector
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// IsZero reports whether no tls option is set
+func (c TLSConfig) IsZero() bool {
+	return c == TLSConfig{}
+}
+
+// Build returns the *tls.Config described by c. CertFile and KeyFile must be set together,
+// an empty CAFile verifies the server against the system roots.
+func (c TLSConfig) Build() (*tls.Config, error) {
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return nil, errors.New("tls cert_file and key_file must be provided together")
+	}
+
+	cfg := &tls.Config{
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read tls ca_file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("tls ca_file %s contains no valid certificate", c.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+	if c.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load tls client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// buildTLS returns the tls config of a connector: c when it's set, otherwise fallback when
+// the legacy enable flag is on, otherwise nil (tls disabled).
+func buildTLS(c TLSConfig, enable bool, fallback *tls.Config) (*tls.Config, error) {
+	if !c.IsZero() {
+		return c.Build()
+	}
+	if enable {
+		return fallback, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,55 @@
+package connector
+
+import (
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTLSConfigBuild(t *testing.T) {
+	badCA := filepath.Join(t.TempDir(), "ca.pem")
+	assert.NoError(t, os.WriteFile(badCA, []byte("not a pem"), 0o600))
+
+	tests := []struct {
+		name    string
+		cfg     TLSConfig
+		wantErr string
+	}{
+		{name: "server name only", cfg: TLSConfig{ServerName: "db.internal"}},
+		{name: "cert without key", cfg: TLSConfig{CertFile: "client.pem"}, wantErr: "must be provided together"},
+		{name: "key without cert", cfg: TLSConfig{KeyFile: "client.key"}, wantErr: "must be provided together"},
+		{name: "missing ca file", cfg: TLSConfig{CAFile: filepath.Join(t.TempDir(), "none.pem")}, wantErr: "read tls ca_file"},
+		{name: "invalid ca file", cfg: TLSConfig{CAFile: badCA}, wantErr: "no valid certificate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := tt.cfg.Build()
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.cfg.ServerName, cfg.ServerName)
+		})
+	}
+}
+
+func TestBuildTLS(t *testing.T) {
+	fallback := &tls.Config{InsecureSkipVerify: true}
+
+	cfg, err := buildTLS(TLSConfig{}, false, fallback)
+	assert.NoError(t, err)
+	assert.Nil(t, cfg)
+
+	cfg, err = buildTLS(TLSConfig{}, true, fallback)
+	assert.NoError(t, err)
+	assert.Same(t, fallback, cfg)
+
+	cfg, err = buildTLS(TLSConfig{ServerName: "cache"}, false, fallback)
+	assert.NoError(t, err)
+	assert.Equal(t, "cache", cfg.ServerName)
+	assert.False(t, cfg.InsecureSkipVerify)
+}
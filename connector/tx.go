@@ -0,0 +1,98 @@
+package connector
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/mbeoliero/kit/log"
+	"github.com/mbeoliero/kit/repox"
+	"gorm.io/gorm"
+)
+
+// WithTx runs fn inside a transaction on db, committing when fn returns nil and
+// rolling back when it returns an error or panics (the panic is re-raised after the rollback).
+//
+// The transaction is stored in the context of tx (see repox.WithGormTx), so nested calls made
+// with tx.Statement.Context, and repox repos used with that context, join the outer transaction
+// instead of starting a new one. Only the outermost call commits or rolls back.
+func WithTx(ctx context.Context, db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	return withTx(ctx, db, nil, fn)
+}
+
+// ErrIsolationLevel is returned by WithTxIsolation when the driver of db doesn't support the level,
+// or when the call would join an outer transaction whose level can't be changed anymore
+var ErrIsolationLevel = errors.New("connector: unsupported transaction isolation level")
+
+// isolationLevels lists the levels the drivers accept, by gorm dialector name.
+// Drivers missing from it are left to reject the levels they don't support on Begin
+var isolationLevels = map[string][]sql.IsolationLevel{
+	"mysql":     {sql.LevelDefault, sql.LevelReadUncommitted, sql.LevelReadCommitted, sql.LevelRepeatableRead, sql.LevelSerializable},
+	"postgres":  {sql.LevelDefault, sql.LevelReadUncommitted, sql.LevelReadCommitted, sql.LevelRepeatableRead, sql.LevelSerializable},
+	"sqlserver": {sql.LevelDefault, sql.LevelReadUncommitted, sql.LevelReadCommitted, sql.LevelRepeatableRead, sql.LevelSnapshot, sql.LevelSerializable},
+	"sqlite":    {sql.LevelDefault, sql.LevelSerializable},
+}
+
+// WithTxIsolation is WithTx running the transaction at level, e.g. sql.LevelRepeatableRead for
+// consistent reporting reads. A level the driver doesn't support fails with ErrIsolationLevel before
+// anything runs, as does a level other than sql.LevelDefault for a call nested in an outer transaction
+func WithTxIsolation(ctx context.Context, db *gorm.DB, level sql.IsolationLevel, fn func(tx *gorm.DB) error) error {
+	if err := checkIsolationLevel(db, level); err != nil {
+		return err
+	}
+	if level != sql.LevelDefault && repox.GetGormTx(ctx) != nil {
+		return fmt.Errorf("%w: %s within an outer transaction", ErrIsolationLevel, level)
+	}
+	return withTx(ctx, db, &sql.TxOptions{Isolation: level}, fn)
+}
+
+// checkIsolationLevel reports ErrIsolationLevel when the driver of db is known not to support level
+func checkIsolationLevel(db *gorm.DB, level sql.IsolationLevel) error {
+	levels, ok := isolationLevels[db.Name()]
+	if !ok {
+		return nil
+	}
+	for _, l := range levels {
+		if l == level {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s on %s", ErrIsolationLevel, level, db.Name())
+}
+
+func withTx(ctx context.Context, db *gorm.DB, opts *sql.TxOptions, fn func(tx *gorm.DB) error) error {
+	if tx := repox.GetGormTx(ctx); tx != nil {
+		return fn(tx.WithContext(ctx))
+	}
+
+	var txOpts []*sql.TxOptions
+	if opts != nil {
+		txOpts = append(txOpts, opts)
+	}
+	tx := db.WithContext(ctx).Begin(txOpts...)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	ctx = repox.WithGormTx(ctx, tx)
+	tx = tx.WithContext(ctx)
+
+	panicked := true
+	defer func() {
+		if panicked {
+			if err := tx.Rollback().Error; err != nil {
+				log.CtxError(ctx, "rollback transaction after panic failed with error %v", err)
+			}
+		}
+	}()
+
+	err := fn(tx)
+	panicked = false
+	if err != nil {
+		if rbErr := tx.Rollback().Error; rbErr != nil {
+			return fmt.Errorf("%w, rollback failed: %v", err, rbErr)
+		}
+		return err
+	}
+	return tx.Commit().Error
+}
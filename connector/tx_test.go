@@ -0,0 +1,188 @@
+package connector
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/mbeoliero/kit/repox"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestWithTxIsolation(t *testing.T) {
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: "user:pass@tcp(127.0.0.1:3306)/test", SkipInitializeWithVersion: true}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	assert.NoError(t, err)
+
+	called := false
+	fn := func(tx *gorm.DB) error {
+		called = true
+		return nil
+	}
+	ctx := context.Background()
+	assert.ErrorIs(t, WithTxIsolation(ctx, db, sql.LevelSnapshot, fn), ErrIsolationLevel)
+	assert.ErrorIs(t, WithTxIsolation(ctx, db, sql.LevelLinearizable, fn), ErrIsolationLevel)
+	assert.False(t, called)
+
+	// a nested call can't change the level of the outer transaction, but can join it
+	outer := repox.WithGormTx(ctx, db)
+	assert.ErrorIs(t, WithTxIsolation(outer, db, sql.LevelRepeatableRead, fn), ErrIsolationLevel)
+	assert.False(t, called)
+	assert.NoError(t, WithTxIsolation(outer, db, sql.LevelDefault, fn))
+	assert.True(t, called)
+
+	assert.NoError(t, checkIsolationLevel(db, sql.LevelRepeatableRead))
+}
+
+// fakeSQL is a database/sql driver recording the statements and transaction calls it receives,
+// it lets the gorm helpers run without a mysql server
+type fakeSQL struct {
+	mu  sync.Mutex
+	ops []string
+}
+
+func (f *fakeSQL) record(op string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.ops = append(f.ops, op)
+}
+
+func (f *fakeSQL) Ops() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.ops...)
+}
+
+func (f *fakeSQL) Connect(context.Context) (driver.Conn, error) {
+	return fakeSQLConn{f}, nil
+}
+
+func (f *fakeSQL) Driver() driver.Driver {
+	return fakeSQLDriver{f}
+}
+
+type fakeSQLDriver struct{ f *fakeSQL }
+
+func (d fakeSQLDriver) Open(string) (driver.Conn, error) {
+	return fakeSQLConn(d), nil
+}
+
+type fakeSQLConn struct{ f *fakeSQL }
+
+func (c fakeSQLConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("fake sql: prepare not supported")
+}
+
+func (c fakeSQLConn) Close() error {
+	return nil
+}
+
+func (c fakeSQLConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c fakeSQLConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	c.f.record("begin")
+	return fakeSQLTx(c), nil
+}
+
+func (c fakeSQLConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.f.record(query)
+	return driver.RowsAffected(1), nil
+}
+
+type fakeSQLTx struct{ f *fakeSQL }
+
+func (tx fakeSQLTx) Commit() error {
+	tx.f.record("commit")
+	return nil
+}
+
+func (tx fakeSQLTx) Rollback() error {
+	tx.f.record("rollback")
+	return nil
+}
+
+// newFakeGorm opens a gorm db with the mysql dialector on top of fakeSQL
+func newFakeGorm(t *testing.T) (*gorm.DB, *fakeSQL) {
+	t.Helper()
+	f := &fakeSQL{}
+	sqlDB := sql.OpenDB(f)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}), &gorm.Config{})
+	assert.NoError(t, err)
+	return db, f
+}
+
+func TestWithTx(t *testing.T) {
+	db, f := newFakeGorm(t)
+	ctx := context.Background()
+
+	assert.NoError(t, WithTx(ctx, db, func(tx *gorm.DB) error {
+		return tx.Exec("INSERT a").Error
+	}))
+	assert.Equal(t, []string{"begin", "INSERT a", "commit"}, f.Ops())
+}
+
+func TestWithTxRollback(t *testing.T) {
+	db, f := newFakeGorm(t)
+	ctx := context.Background()
+
+	failed := errors.New("failed")
+	err := WithTx(ctx, db, func(tx *gorm.DB) error {
+		if err := tx.Exec("INSERT a").Error; err != nil {
+			return err
+		}
+		return failed
+	})
+	assert.ErrorIs(t, err, failed)
+	assert.Equal(t, []string{"begin", "INSERT a", "rollback"}, f.Ops())
+}
+
+func TestWithTxPanic(t *testing.T) {
+	db, f := newFakeGorm(t)
+	ctx := context.Background()
+
+	assert.PanicsWithValue(t, "boom", func() {
+		_ = WithTx(ctx, db, func(tx *gorm.DB) error {
+			tx.Exec("INSERT a")
+			panic("boom")
+		})
+	})
+	assert.Equal(t, []string{"begin", "INSERT a", "rollback"}, f.Ops())
+}
+
+func TestWithTxNested(t *testing.T) {
+	db, f := newFakeGorm(t)
+	ctx := context.Background()
+
+	// the nested call joins the outer transaction, only the outer call commits
+	assert.NoError(t, WithTx(ctx, db, func(tx *gorm.DB) error {
+		assert.NotNil(t, repox.GetGormTx(tx.Statement.Context))
+		if err := tx.Exec("INSERT a").Error; err != nil {
+			return err
+		}
+		return WithTx(tx.Statement.Context, db, func(inner *gorm.DB) error {
+			return inner.Exec("INSERT b").Error
+		})
+	}))
+	assert.Equal(t, []string{"begin", "INSERT a", "INSERT b", "commit"}, f.Ops())
+
+	// an error of the nested call rolls back the whole transaction
+	f.ops = nil
+	failed := errors.New("failed")
+	err := WithTx(ctx, db, func(tx *gorm.DB) error {
+		tx.Exec("INSERT a")
+		return WithTx(tx.Statement.Context, db, func(inner *gorm.DB) error {
+			inner.Exec("INSERT b")
+			return failed
+		})
+	})
+	assert.ErrorIs(t, err, failed)
+	assert.Equal(t, []string{"begin", "INSERT a", "INSERT b", "rollback"}, f.Ops())
+}
@@ -0,0 +1,63 @@
+package connector
+
+import (
+	"errors"
+	"fmt"
+)
+
+// configErrors collects the problems of a config, each naming the offending field by its config key
+type configErrors struct {
+	kind string
+	errs []error
+}
+
+func (c *configErrors) check(ok bool, field, format string, args ...any) {
+	if !ok {
+		c.errs = append(c.errs, fmt.Errorf("%s config: %s %s", c.kind, field, fmt.Sprintf(format, args...)))
+	}
+}
+
+func (c *configErrors) tls(t TLSConfig) {
+	c.check((t.CertFile == "") == (t.KeyFile == ""), "tls.cert_file", "and tls.key_file must be provided together")
+}
+
+func (c *configErrors) err() error {
+	return errors.Join(c.errs...)
+}
+
+// Validate reports every missing required field and out of range value of the config, InitGorm calls it
+// before connecting. Either path or write_path must be set.
+func (m MysqlConfig) Validate() error {
+	c := configErrors{kind: "mysql"}
+	c.check(m.Dbname != "", "dbname", "is required")
+	c.check(m.Path != "" || m.WritePath != "", "path", "or write_path is required")
+	c.check(m.MaxIdleConns >= 0, "max_idle_conns", "must not be negative, got %d", m.MaxIdleConns)
+	c.check(m.MaxOpenConns >= 0, "max_open_conns", "must not be negative, got %d", m.MaxOpenConns)
+	c.check(m.ConnMaxLifetime >= 0, "conn_max_lifetime", "must not be negative, got %d", m.ConnMaxLifetime)
+	c.tls(m.TLS)
+	return c.err()
+}
+
+// Validate reports every missing required field and out of range value of the config, InitRedis and
+// InitClusterRedis call it before connecting.
+func (r RedisConfig) Validate() error {
+	c := configErrors{kind: "redis"}
+	c.check(r.Addr != "", "addr", "is required")
+	c.check(r.DB >= 0, "db", "must not be negative, got %d", r.DB)
+	c.check(!r.IsCluster || r.DB == 0, "db", "must be 0 in cluster mode, got %d", r.DB)
+	c.check(r.PoolSize >= 0, "pool_size", "must not be negative, got %d", r.PoolSize)
+	c.check(r.DialTimeout >= 0, "dial_timeout", "must not be negative, got %d", r.DialTimeout)
+	c.check(r.ReadTimeout >= -1, "read_timeout", "must be -1 or more, got %d", r.ReadTimeout)
+	c.check(r.WriteTimeout >= -1, "write_timeout", "must be -1 or more, got %d", r.WriteTimeout)
+	c.tls(r.TLS)
+	return c.err()
+}
+
+// Validate reports every missing required field of the config, InitMongo calls it before connecting.
+func (m MongoConfig) Validate() error {
+	c := configErrors{kind: "mongo"}
+	c.check(m.Address != "", "address", "is required")
+	c.check(m.Password == "" || m.Username != "", "username", "is required when password is set")
+	c.tls(m.TLS)
+	return c.err()
+}
@@ -0,0 +1,44 @@
+package connector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMysqlConfigValidate(t *testing.T) {
+	assert.NoError(t, MysqlConfig{Dbname: "app", Path: "127.0.0.1:3306"}.Validate())
+	assert.NoError(t, MysqlConfig{Dbname: "app", WritePath: "a:3306,b:3306"}.Validate())
+
+	err := MysqlConfig{MaxOpenConns: -1, TLS: TLSConfig{CertFile: "client.pem"}}.Validate()
+	assert.EqualError(t, err, "mysql config: dbname is required\n"+
+		"mysql config: path or write_path is required\n"+
+		"mysql config: max_open_conns must not be negative, got -1\n"+
+		"mysql config: tls.cert_file and tls.key_file must be provided together")
+
+	_, err = InitGorm(MysqlConfig{Path: "127.0.0.1:3306"})
+	assert.EqualError(t, err, "mysql config: dbname is required")
+}
+
+func TestRedisConfigValidate(t *testing.T) {
+	assert.NoError(t, RedisConfig{Addr: "127.0.0.1:6379", ReadTimeout: -1}.Validate())
+
+	err := RedisConfig{DB: 2, IsCluster: true, PoolSize: -5, WriteTimeout: -2}.Validate()
+	assert.EqualError(t, err, "redis config: addr is required\n"+
+		"redis config: db must be 0 in cluster mode, got 2\n"+
+		"redis config: pool_size must not be negative, got -5\n"+
+		"redis config: write_timeout must be -1 or more, got -2")
+
+	_, err = InitRedis(RedisConfig{})
+	assert.EqualError(t, err, "redis config: addr is required")
+	_, err = InitClusterRedis(RedisConfig{})
+	assert.EqualError(t, err, "redis config: addr is required")
+}
+
+func TestMongoConfigValidate(t *testing.T) {
+	assert.NoError(t, MongoConfig{Address: "localhost:27017"}.Validate())
+
+	_, err := InitMongo(MongoConfig{Password: "secret"})
+	assert.EqualError(t, err, "mongo config: address is required\n"+
+		"mongo config: username is required when password is set")
+}
@@ -0,0 +1,88 @@
+package connector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/mbeoliero/kit/log"
+	"github.com/redis/go-redis/v9"
+)
+
+// warmupTimeout bounds the whole warmup of a pool
+const warmupTimeout = 10 * time.Second
+
+// warmupRedis opens o.warmup connections of client, on every master of a cluster
+func warmupRedis(client redis.UniversalClient, o *initOptions) error {
+	if o.warmup <= 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), warmupTimeout)
+	defer cancel()
+
+	var err error
+	switch c := client.(type) {
+	case *redis.ClusterClient:
+		err = c.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			return warmupRedisNode(ctx, node, o.warmup)
+		})
+	case *redis.Client:
+		err = warmupRedisNode(ctx, c, o.warmup)
+	}
+	return warmupDone("redis", o, err)
+}
+
+// warmupRedisNode holds n connections of node at once, so the pool can't hand out the same one twice
+func warmupRedisNode(ctx context.Context, node *redis.Client, n int) error {
+	conns := make([]*redis.Conn, 0, n)
+	defer func() {
+		for _, conn := range conns {
+			_ = conn.Close()
+		}
+	}()
+	for i := 0; i < n; i++ {
+		conn := node.Conn()
+		conns = append(conns, conn)
+		if err := conn.Ping(ctx).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// warmupSQL opens o.warmup connections of db, holding them at once like warmupRedisNode
+func warmupSQL(db *sql.DB, o *initOptions) error {
+	if o.warmup <= 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), warmupTimeout)
+	defer cancel()
+
+	conns := make([]*sql.Conn, 0, o.warmup)
+	var err error
+	for i := 0; i < o.warmup && err == nil; i++ {
+		var conn *sql.Conn
+		if conn, err = db.Conn(ctx); err == nil {
+			conns = append(conns, conn)
+			err = conn.PingContext(ctx)
+		}
+	}
+	for _, conn := range conns {
+		_ = conn.Close()
+	}
+	return warmupDone("mysql", o, err)
+}
+
+// warmupDone logs the outcome of a warmup, err is only returned with WithWarmupStrict
+func warmupDone(db string, o *initOptions, err error) error {
+	if err == nil {
+		log.Info("warmup %s pool with %d connections done", db, o.warmup)
+		return nil
+	}
+	if o.warmupStrict {
+		return fmt.Errorf("warmup %s pool: %w", db, err)
+	}
+	log.Warn("warmup %s pool failed with error %v, continue with a cold pool", db, err)
+	return nil
+}
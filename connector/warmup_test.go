@@ -0,0 +1,24 @@
+package connector
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithWarmupRedis(t *testing.T) {
+	s := miniredis.RunT(t)
+
+	client, err := InitRedis(RedisConfig{Addr: s.Addr(), PoolSize: 10, DisableTrace: true}, WithWarmup(5))
+	assert.NoError(t, err)
+	defer func() { _ = client.Close() }()
+	assert.GreaterOrEqual(t, client.PoolStats().TotalConns, uint32(5))
+}
+
+func TestWarmupDone(t *testing.T) {
+	failed := errors.New("dial failed")
+	assert.NoError(t, warmupDone("redis", newOptions(WithWarmup(5)), failed))
+	assert.ErrorIs(t, warmupDone("redis", newOptions(WithWarmup(5), WithWarmupStrict()), failed), failed)
+}
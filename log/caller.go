@@ -0,0 +1,74 @@
+package log
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+)
+
+var (
+	callerWithFunc atomic.Bool
+	callerDisabled atomic.Bool
+)
+
+// zerolog.CallerMarshalFunc is global and read without synchronization by every log line, so it's
+// set once here and SetCallerWithFunc only flips callerWithFunc. formatCaller matches zerolog's
+// default format while the option is off.
+func init() {
+	zerolog.CallerMarshalFunc = formatCaller
+}
+
+// SetCallerWithFunc appends the function name to the caller field, e.g.
+// /app/handler/user.go:42:handler.(*UserHandler).Get. It resolves the function of every
+// log line, so it's meant for debugging and is off by default.
+// It's safe to call while logging, the zerolog.CallerMarshalFunc of the package is left in place.
+func SetCallerWithFunc(enable bool) {
+	callerWithFunc.Store(enable)
+}
+
+// SetCaller enables or disables resolving the caller of every log line, enabled by default.
+// Resolving it walks the stack, services logging at a very high rate can disable it to trade the
+// caller field, then emitted as "-", for throughput.
+func SetCaller(enable bool) {
+	callerDisabled.Store(!enable)
+}
+
+func callerEnabled() bool {
+	return !callerDisabled.Load()
+}
+
+// callerSkipFrameCount skips the frames between the hook and the user code calling the log function
+const callerSkipFrameCount = 6
+
+// callerHook adds the caller to zerolog events unless SetCaller(false) is set, it replaces
+// CallerWithSkipFrameCount, which can't be turned off once the logger is built
+type callerHook struct{}
+
+func (callerHook) Run(e *zerolog.Event, _ zerolog.Level, _ string) {
+	if callerEnabled() {
+		e.Caller(callerSkipFrameCount)
+	}
+}
+
+// formatCaller returns file:line, followed by :function when SetCallerWithFunc is enabled
+func formatCaller(pc uintptr, file string, line int) string {
+	caller := file + ":" + strconv.Itoa(line)
+	if !callerWithFunc.Load() {
+		return caller
+	}
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		caller += ":" + shortFuncName(fn.Name())
+	}
+	return caller
+}
+
+// shortFuncName trims the import path of name, github.com/a/b/pkg.(*T).M becomes pkg.(*T).M
+func shortFuncName(name string) string {
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
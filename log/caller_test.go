@@ -0,0 +1,101 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetCallerWithFunc(t *testing.T) {
+	defer func() {
+		SetCallerWithFunc(false)
+	}()
+
+	for _, typ := range []LoggerType{LoggerTypeZerolog, LoggerTypeLogrus} {
+		useLogger(t, typ)
+		var buf bytes.Buffer
+		SetOutput(&buf)
+
+		SetCallerWithFunc(true)
+		Info("with func")
+		assert.Regexp(t, `caller_test\.go:\d+:log\.TestSetCallerWithFunc `, buf.String())
+
+		buf.Reset()
+		SetCallerWithFunc(false)
+		Info("without func")
+		assert.Regexp(t, `caller_test\.go:\d+ `, buf.String())
+	}
+}
+
+func TestSetCallerWithFuncConcurrent(t *testing.T) {
+	defer func() {
+		SetCallerWithFunc(false)
+	}()
+	useLogger(t, LoggerTypeZerolog)
+	SetOutput(io.Discard)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			SetCallerWithFunc(i%2 == 0)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			Info("concurrent")
+		}
+	}()
+	wg.Wait()
+}
+
+func TestShortFuncName(t *testing.T) {
+	assert.Equal(t, "pkg.(*T).M", shortFuncName("github.com/a/b/pkg.(*T).M"))
+	assert.Equal(t, "main.main", shortFuncName("main.main"))
+}
+
+func TestSetCaller(t *testing.T) {
+	defer func() {
+		SetCaller(true)
+	}()
+
+	for _, typ := range []LoggerType{LoggerTypeZerolog, LoggerTypeLogrus} {
+		useLogger(t, typ)
+		var buf bytes.Buffer
+		SetOutput(&buf)
+
+		SetCaller(false)
+		Info("without caller")
+		assert.NotContains(t, buf.String(), "caller_test.go")
+		assert.Contains(t, buf.String(), " - - {} : without caller")
+
+		buf.Reset()
+		SetCaller(true)
+		Info("with caller")
+		assert.Regexp(t, `caller_test\.go:\d+ `, buf.String())
+	}
+}
+
+func BenchmarkSetCaller(b *testing.B) {
+	defer func() {
+		SetCaller(true)
+	}()
+	useLogger(b, LoggerTypeZerolog)
+	SetOutput(io.Discard)
+
+	for _, enable := range []bool{true, false} {
+		b.Run(fmt.Sprintf("caller=%v", enable), func(b *testing.B) {
+			SetCaller(enable)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				Info("benchmark line")
+			}
+		})
+	}
+}
@@ -2,31 +2,39 @@ package log
 
 import "context"
 
-// AppendLogExtras 注意，此函数并非并发安全，请勿在初始化之外等常见进行写入
+// customFieldsCtxKey is the context key of the custom fields, an unexported type
+// can't collide with keys of other packages
+type customFieldsCtxKey struct{}
+
+// AppendLogExtras returns a context whose custom fields are the existing ones merged with extra,
+// extra wins on duplicate keys. The fields of ctx itself are left untouched.
 func AppendLogExtras(ctx context.Context, extra map[string]string) context.Context {
-	extraData, ok := ctx.Value(CustomFieldsKey).(map[string]string)
-	if !ok {
-		extraData = make(map[string]string)
-		ctx = context.WithValue(ctx, CustomFieldsKey, extraData)
+	current := GetAllCustomFields(ctx)
+	merged := make(map[string]string, len(current)+len(extra))
+	for k, v := range current {
+		merged[k] = v
 	}
 	for k, v := range extra {
-		extraData[k] = v
+		merged[k] = v
 	}
-	return ctx
+	return WithCustomFields(ctx, merged)
 }
 
-// AppendLogKv 注意，此函数并非并发安全，请勿在初始化之外等常见进行写入
+// AppendLogKv returns a context whose custom fields are the existing ones plus key=value.
+// The fields of ctx itself are left untouched.
 func AppendLogKv(ctx context.Context, key, value string) context.Context {
-	extraData, ok := ctx.Value(CustomFieldsKey).(map[string]string)
-	if !ok {
-		extraData = make(map[string]string)
-		ctx = context.WithValue(ctx, CustomFieldsKey, extraData)
-	}
-	extraData[key] = value
-	return ctx
+	return AppendLogExtras(ctx, map[string]string{key: value})
+}
+
+// WithCustomFields returns a context carrying fields as its custom fields, replacing any existing ones.
+// fields must not be modified afterwards.
+func WithCustomFields(ctx context.Context, fields map[string]string) context.Context {
+	return context.WithValue(ctx, customFieldsCtxKey{}, fields)
 }
 
+// GetAllCustomFields returns the custom fields of ctx, nil when there are none.
+// The returned map must not be modified.
 func GetAllCustomFields(ctx context.Context) map[string]string {
-	extraData, _ := ctx.Value(CustomFieldsKey).(map[string]string)
+	extraData, _ := ctx.Value(customFieldsCtxKey{}).(map[string]string)
 	return extraData
 }
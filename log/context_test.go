@@ -0,0 +1,28 @@
+package log
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCustomFieldsNoCollision(t *testing.T) {
+	// another package storing a value under the same plain string as the field name
+	foreign := map[string]string{"foreign": "x"}
+	ctx := context.WithValue(context.Background(), CustomFieldsKey, foreign)
+	assert.Nil(t, GetAllCustomFields(ctx))
+
+	ctx = AppendLogKv(ctx, "user_id", "1")
+	assert.Equal(t, map[string]string{"user_id": "1"}, GetAllCustomFields(ctx))
+	assert.Equal(t, foreign, ctx.Value(CustomFieldsKey))
+}
+
+func TestAppendLogKvMerge(t *testing.T) {
+	parent := AppendLogExtras(context.Background(), map[string]string{"user_id": "1", "app_id": "2"})
+	child := AppendLogKv(parent, "user_id", "3")
+	child = AppendLogKv(child, "region", "eu")
+
+	assert.Equal(t, map[string]string{"user_id": "3", "app_id": "2", "region": "eu"}, GetAllCustomFields(child))
+	assert.Equal(t, map[string]string{"user_id": "1", "app_id": "2"}, GetAllCustomFields(parent))
+}
@@ -0,0 +1,70 @@
+package log
+
+import "context"
+
+// CtxLogger is a logger bound to a context, every line it logs carries the custom fields and
+// the trace id of the context. It logs through the default logger when the line is emitted,
+// so the global level and output apply. (Logger being the wrapper of the backends, the bound
+// logger has its own type.)
+type CtxLogger struct {
+	ctx context.Context
+}
+
+// FromContext returns a logger bound to ctx, e.g.
+//
+//	l := log.FromContext(ctx)
+//	l.Info("user %d updated", id)
+func FromContext(ctx context.Context) *CtxLogger {
+	return &CtxLogger{ctx: ctx}
+}
+
+// With returns a logger bound to ctx with kv, alternating keys and values, added to its custom fields.
+// e.g. log.With(ctx, "user_id", 42).Info("renamed")
+func With(ctx context.Context, kv ...any) *CtxLogger {
+	return &CtxLogger{ctx: withKV(ctx, kv)}
+}
+
+// With returns a copy of l with kv added to its custom fields, l itself is left untouched.
+func (l *CtxLogger) With(kv ...any) *CtxLogger {
+	return &CtxLogger{ctx: withKV(l.ctx, kv)}
+}
+
+// Context returns the context l is bound to, carrying the custom fields added by With.
+func (l *CtxLogger) Context() context.Context {
+	return l.ctx
+}
+
+// Fatal calls the default logger's CtxFatalf method and then os.Exit(1), see OnFatal.
+func (l *CtxLogger) Fatal(format string, v ...interface{}) {
+	getDefaultLogger().CtxFatalf(l.ctx, format, v...)
+}
+
+// Error calls the default logger's CtxErrorf method.
+func (l *CtxLogger) Error(format string, v ...interface{}) {
+	getDefaultLogger().CtxErrorf(l.ctx, format, v...)
+}
+
+// Warn calls the default logger's CtxWarnf method.
+func (l *CtxLogger) Warn(format string, v ...interface{}) {
+	getDefaultLogger().CtxWarnf(l.ctx, format, v...)
+}
+
+// Notice calls the default logger's CtxNoticef method.
+func (l *CtxLogger) Notice(format string, v ...interface{}) {
+	getDefaultLogger().CtxNoticef(l.ctx, format, v...)
+}
+
+// Info calls the default logger's CtxInfof method.
+func (l *CtxLogger) Info(format string, v ...interface{}) {
+	getDefaultLogger().CtxInfof(l.ctx, format, v...)
+}
+
+// Debug calls the default logger's CtxDebugf method.
+func (l *CtxLogger) Debug(format string, v ...interface{}) {
+	getDefaultLogger().CtxDebugf(l.ctx, format, v...)
+}
+
+// Trace calls the default logger's CtxTracef method.
+func (l *CtxLogger) Trace(format string, v ...interface{}) {
+	getDefaultLogger().CtxTracef(l.ctx, format, v...)
+}
@@ -0,0 +1,42 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/cloudwego/kitex/pkg/klog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCtxLogger(t *testing.T) {
+	for _, typ := range []LoggerType{LoggerTypeZerolog, LoggerTypeLogrus} {
+		useLogger(t, typ).SetLevel(klog.LevelInfo)
+		var buf bytes.Buffer
+		SetOutput(&buf)
+
+		ctx := AppendLogKv(context.Background(), "trace", "a")
+		l := With(ctx, "user_id", 42)
+		l.Info("renamed %s", "bob")
+
+		out := buf.String()
+		assert.Contains(t, out, `"trace":"a"`)
+		assert.Contains(t, out, `"user_id":"42"`)
+		assert.Regexp(t, `ctx_logger_test\.go:\d+ `, out)
+		assert.Contains(t, out, ": renamed bob\n")
+
+		buf.Reset()
+		l.Debug("dropped")
+		assert.Empty(t, buf.String())
+
+		buf.Reset()
+		l.With("action", "rename").Warn("again")
+		assert.Contains(t, buf.String(), `"action":"rename"`)
+		assert.NotContains(t, GetAllCustomFields(l.Context()), "action")
+
+		buf.Reset()
+		FromContext(ctx).Info("plain")
+		assert.Contains(t, buf.String(), `"trace":"a"`)
+		assert.NotContains(t, buf.String(), "user_id")
+	}
+}
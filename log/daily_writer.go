@@ -0,0 +1,86 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/natefinch/lumberjack"
+)
+
+const dailyFileDateFormat = "2006-01-02"
+
+// dailyWriter wraps lumberjack and switches to a dated file whenever the day changes,
+// lumberjack itself only rotates by size.
+type dailyWriter struct {
+	mu       sync.Mutex
+	roller   *lumberjack.Logger
+	fileName string
+	day      string
+	now      func() time.Time
+}
+
+func newDailyWriter(roller *lumberjack.Logger) *dailyWriter {
+	return &dailyWriter{
+		roller:   roller,
+		fileName: roller.Filename,
+		now:      time.Now,
+	}
+}
+
+func (w *dailyWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if day := w.now().Format(dailyFileDateFormat); day != w.day {
+		if w.day != "" {
+			_ = w.roller.Close()
+		}
+		w.day = day
+		w.roller.Filename = datedFileName(w.fileName, day)
+		w.prune()
+	}
+	return w.roller.Write(p)
+}
+
+// prune removes the files of the days older than MaxAge days, along with their lumberjack backups.
+// lumberjack only cleans up the backups of the file it writes, the one of the current day.
+func (w *dailyWriter) prune() {
+	if w.roller.MaxAge <= 0 {
+		return
+	}
+	cutoff := w.now().AddDate(0, 0, -w.roller.MaxAge).Format(dailyFileDateFormat)
+	dir := filepath.Dir(w.fileName)
+	ext := filepath.Ext(w.fileName)
+	prefix := strings.TrimSuffix(filepath.Base(w.fileName), ext) + "-"
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) || len(name) < len(prefix)+len(dailyFileDateFormat) {
+			continue
+		}
+		day := name[len(prefix) : len(prefix)+len(dailyFileDateFormat)]
+		if _, err := time.Parse(dailyFileDateFormat, day); err != nil || day >= cutoff {
+			continue
+		}
+		_ = os.Remove(filepath.Join(dir, name))
+	}
+}
+
+func (w *dailyWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.roller.Close()
+}
+
+// datedFileName inserts the day before the extension: app.log -> app-2006-01-02.log
+func datedFileName(fileName, day string) string {
+	ext := filepath.Ext(fileName)
+	return strings.TrimSuffix(fileName, ext) + "-" + day + ext
+}
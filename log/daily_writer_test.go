@@ -0,0 +1,58 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/natefinch/lumberjack"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDailyWriter(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2024, 3, 1, 23, 59, 0, 0, time.Local)
+
+	w := newDailyWriter(&lumberjack.Logger{Filename: filepath.Join(dir, "app.log")})
+	w.now = func() time.Time { return now }
+	defer w.Close()
+
+	_, err := w.Write([]byte("day one\n"))
+	assert.NoError(t, err)
+
+	now = now.Add(2 * time.Minute)
+	_, err = w.Write([]byte("day two\n"))
+	assert.NoError(t, err)
+
+	b, err := os.ReadFile(filepath.Join(dir, "app-2024-03-01.log"))
+	assert.NoError(t, err)
+	assert.Equal(t, "day one\n", string(b))
+
+	b, err = os.ReadFile(filepath.Join(dir, "app-2024-03-02.log"))
+	assert.NoError(t, err)
+	assert.Equal(t, "day two\n", string(b))
+}
+
+func TestDailyWriterMaxAge(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"app-2024-02-20.log", "app-2024-02-20-2024-02-20T10-00-00.000.log.gz", "app-2024-02-27.log", "other-2024-02-01.log"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("old\n"), 0o644))
+	}
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.Local)
+
+	w := newDailyWriter(&lumberjack.Logger{Filename: filepath.Join(dir, "app.log"), MaxAge: 3})
+	w.now = func() time.Time { return now }
+	defer w.Close()
+
+	_, err := w.Write([]byte("today\n"))
+	assert.NoError(t, err)
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.ElementsMatch(t, []string{"app-2024-02-27.log", "app-2024-03-01.log", "other-2024-02-01.log"}, names)
+}
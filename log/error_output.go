@@ -0,0 +1,44 @@
+package log
+
+import (
+	"io"
+	"sync/atomic"
+)
+
+// writerHolder lets an io.Writer be stored in an atomic.Pointer
+type writerHolder struct {
+	io.Writer
+}
+
+var errorOutput atomic.Pointer[writerHolder]
+
+// SetErrorOutput routes the warn, error, fatal and panic lines to w, e.g. os.Stderr so k8s can tell
+// the streams apart, the other lines keep going to the output of the logger. A nil w sends everything
+// to the output again, the default. It applies to both backends, with logrus through the Formatter of
+// the package.
+func SetErrorOutput(w io.Writer) {
+	if w == nil {
+		errorOutput.Store(nil)
+		return
+	}
+	errorOutput.Store(&writerHolder{w})
+}
+
+// isErrorLevel reports whether the lower case level name goes to the error output, logrus names
+// the warn level "warning"
+func isErrorLevel(level string) bool {
+	switch level {
+	case "warn", "warning", "error", "fatal", "panic":
+		return true
+	}
+	return false
+}
+
+// outputFor returns the writer of a line at the lower case level: the error output when one is set
+// and the level is warn or above, out otherwise
+func outputFor(out io.Writer, level string) io.Writer {
+	if h := errorOutput.Load(); h != nil && isErrorLevel(level) {
+		return h.Writer
+	}
+	return out
+}
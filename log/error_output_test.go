@@ -0,0 +1,67 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetErrorOutput(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	zlog := zerolog.New(newCustomWriter(&stdout)).Hook(customFieldsHook{})
+
+	SetErrorOutput(&stderr)
+	defer SetErrorOutput(nil)
+	zlog.Debug().Msg("debug line")
+	zlog.Info().Msg("info line")
+	zlog.WithLevel(zerolog.NoLevel).Str(zerolog.LevelFieldName, levelNoticeValue).Msg("notice line")
+	zlog.Warn().Msg("warn line")
+	zlog.Error().Msg("error line")
+
+	assert.Equal(t, 3, strings.Count(stdout.String(), "\n"))
+	assert.Contains(t, stdout.String(), "info line")
+	assert.Contains(t, stdout.String(), "notice line")
+	assert.NotContains(t, stdout.String(), "warn line")
+	assert.Equal(t, 2, strings.Count(stderr.String(), "\n"))
+	assert.Contains(t, stderr.String(), "warn line")
+	assert.Contains(t, stderr.String(), "error line")
+
+	// without an error output everything goes to the output
+	SetErrorOutput(nil)
+	stdout.Reset()
+	stderr.Reset()
+	zlog.Error().Msg("error line")
+	assert.Contains(t, stdout.String(), "error line")
+	assert.Empty(t, stderr.String())
+}
+
+func TestSetErrorOutputLogrus(t *testing.T) {
+	useLogger(t, LoggerTypeLogrus)
+	var stdout, stderr bytes.Buffer
+	SetOutput(&stdout)
+
+	SetErrorOutput(&stderr)
+	defer SetErrorOutput(nil)
+	Info("info line")
+	Notice("notice line")
+	Warn("warn line")
+	Error("error line")
+
+	assert.Equal(t, 2, strings.Count(stdout.String(), "\n"))
+	assert.Contains(t, stdout.String(), "info line")
+	assert.Contains(t, stdout.String(), "notice line")
+	assert.NotContains(t, stdout.String(), "error line")
+	assert.Equal(t, 2, strings.Count(stderr.String(), "\n"))
+	assert.Contains(t, stderr.String(), "warn line")
+	assert.Contains(t, stderr.String(), "error line")
+
+	SetErrorOutput(nil)
+	stdout.Reset()
+	stderr.Reset()
+	Error("error line")
+	assert.Contains(t, stdout.String(), "error line")
+	assert.Empty(t, stderr.String())
+}
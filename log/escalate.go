@@ -0,0 +1,101 @@
+package log
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// escalation lowers the level to debug once threshold error lines were seen within window
+type escalation struct {
+	threshold int
+	window    time.Duration
+	hold      time.Duration
+
+	mu        sync.Mutex
+	times     []time.Time // the times of the last threshold error lines, a ring
+	next      int
+	escalated bool
+	stopped   bool
+	restoreTo Level
+	timer     *time.Timer
+
+	removeHook func()
+}
+
+var currentEscalation atomic.Pointer[escalation]
+
+// EscalateOnErrors lowers the level to debug for hold once threshold error or fatal lines were logged
+// within window, so an incident is captured with its context, then restores the level it found.
+// Errors during the hold extend it rather than triggering again. The level set with SetLevel meanwhile
+// is overwritten by the restore. threshold <= 0 disables it, restoring the level right away when needed
+// and unregistering the hook counting the lines, see AddHook.
+func EscalateOnErrors(threshold int, window, hold time.Duration) {
+	var e *escalation
+	if threshold > 0 {
+		e = &escalation{threshold: threshold, window: window, hold: hold, times: make([]time.Time, 0, threshold)}
+		e.removeHook = addHook(func(level Level, msg string, fields map[string]string) {
+			if level >= LevelError {
+				e.observe(time.Now())
+			}
+		})
+	}
+	if prev := currentEscalation.Swap(e); prev != nil {
+		prev.stop()
+	}
+}
+
+// stop unregisters the hook and restores the level, lines already queued for the hook are ignored
+func (e *escalation) stop() {
+	e.removeHook()
+	e.mu.Lock()
+	e.stopped = true
+	e.mu.Unlock()
+	e.restore()
+}
+
+// observe records an error line at t and escalates when the window holds threshold of them
+func (e *escalation) observe(t time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.stopped {
+		return
+	}
+	if len(e.times) < e.threshold {
+		e.times = append(e.times, t)
+	} else {
+		e.times[e.next] = t
+		e.next = (e.next + 1) % e.threshold
+	}
+	// with a full ring e.times[e.next] is the oldest of the last threshold lines
+	if len(e.times) < e.threshold || t.Sub(e.times[e.next]) > e.window {
+		return
+	}
+
+	if e.escalated {
+		e.timer.Reset(e.hold)
+		return
+	}
+	e.escalated = true
+	e.restoreTo = GetLogLevel()
+	if e.restoreTo > LevelDebug {
+		SetLevel(LevelDebug)
+	}
+	e.timer = time.AfterFunc(e.hold, e.restore)
+	getDefaultLogger().Noticef("log level lowered to debug for %s after %d errors within %s", e.hold, e.threshold, e.window)
+}
+
+// restore puts the level found on escalation back
+func (e *escalation) restore() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.escalated {
+		return
+	}
+	e.escalated = false
+	e.timer.Stop()
+	e.times = e.times[:0]
+	e.next = 0
+	SetLevel(e.restoreTo)
+	getDefaultLogger().Noticef("log level restored after the error escalation")
+}
@@ -0,0 +1,69 @@
+package log
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscalateOnErrors(t *testing.T) {
+	prevLevel := GetLogLevel()
+	defer func() {
+		EscalateOnErrors(0, 0, 0)
+		SetLevel(prevLevel)
+	}()
+
+	useLogger(t, LoggerTypeZerolog)
+	var out lockedBuffer
+	SetOutput(&out)
+	SetLevel(LevelWarn)
+
+	EscalateOnErrors(3, time.Minute, 100*time.Millisecond)
+	Error("first")
+	Error("second")
+	flushHooks()
+	assert.Equal(t, LevelWarn, GetLogLevel())
+
+	Error("third")
+	flushHooks()
+	assert.Equal(t, LevelDebug, GetLogLevel())
+	Debug("captured")
+	assert.Contains(t, out.String(), "captured")
+
+	// errors during the hold extend it, the level found first is the one restored
+	Error("fourth")
+	flushHooks()
+	assert.Equal(t, LevelDebug, GetLogLevel())
+	assert.Eventually(t, func() bool { return GetLogLevel() == LevelWarn }, time.Second, 5*time.Millisecond)
+}
+
+func TestEscalateOnErrorsDisable(t *testing.T) {
+	prevLevel := GetLogLevel()
+	defer SetLevel(prevLevel)
+
+	useLogger(t, LoggerTypeZerolog)
+	SetOutput(io.Discard)
+	SetLevel(LevelWarn)
+
+	hooksMu.RLock()
+	n := len(hooks)
+	hooksMu.RUnlock()
+
+	EscalateOnErrors(1, time.Minute, time.Minute)
+	EscalateOnErrors(2, time.Minute, time.Minute)
+	hooksMu.RLock()
+	assert.Len(t, hooks, n+1)
+	hooksMu.RUnlock()
+
+	EscalateOnErrors(0, 0, 0)
+	hooksMu.RLock()
+	assert.Len(t, hooks, n)
+	hooksMu.RUnlock()
+
+	Error("first")
+	Error("second")
+	flushHooks()
+	assert.Equal(t, LevelWarn, GetLogLevel())
+}
@@ -0,0 +1,86 @@
+package log
+
+import (
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const defaultFatalTimeout = 5 * time.Second
+
+var (
+	fatalMu       sync.Mutex
+	fatalHandlers []func()
+	fatalTimeout  = defaultFatalTimeout
+
+	// osExit is replaced in tests
+	osExit = os.Exit
+)
+
+// OnFatal registers fn to run before the process exits on Fatal/CtxFatal.
+//
+// Ordering on a fatal log: the fatal line is written, the pending hooks (see AddHook) are
+// delivered and the output is synced, then the handlers run one by one in registration order
+// and finally the process exits with code 1. Flushing and the handlers share the timeout set
+// by SetFatalTimeout (5s by default), the process exits when it expires even if a handler is
+// still running. A panicking handler doesn't prevent the following ones from running.
+func OnFatal(fn func()) {
+	if fn == nil {
+		return
+	}
+	fatalMu.Lock()
+	fatalHandlers = append(fatalHandlers, fn)
+	fatalMu.Unlock()
+}
+
+// SetFatalTimeout sets how long a fatal log waits for flushing and the OnFatal handlers
+func SetFatalTimeout(d time.Duration) {
+	fatalMu.Lock()
+	fatalTimeout = d
+	fatalMu.Unlock()
+}
+
+// exitFatal flushes the logger, runs the OnFatal handlers and exits with code
+func exitFatal(code int) {
+	fatalMu.Lock()
+	handlers := fatalHandlers
+	timeout := fatalTimeout
+	fatalMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		flushHooks()
+		syncOutput()
+		for _, fn := range handlers {
+			callFatalHandler(fn)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+	osExit(code)
+}
+
+func callFatalHandler(fn func()) {
+	defer func() {
+		_ = recover()
+	}()
+	fn()
+}
+
+// syncOutput syncs the output and the error output of the logger when they support it, e.g. *os.File
+func syncOutput() {
+	outputs := []io.Writer{GetLogger().output()}
+	if h := errorOutput.Load(); h != nil {
+		outputs = append(outputs, h.Writer)
+	}
+	for _, out := range outputs {
+		if s, ok := out.(interface{ Sync() error }); ok {
+			_ = s.Sync()
+		}
+	}
+}
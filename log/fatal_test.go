@@ -0,0 +1,89 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/kitex/pkg/klog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOnFatal(t *testing.T) {
+	prevHandlers, prevExit := fatalHandlers, osExit
+	defer func() {
+		fatalHandlers, osExit = prevHandlers, prevExit
+	}()
+
+	for _, typ := range []LoggerType{LoggerTypeZerolog, LoggerTypeLogrus} {
+		useLogger(t, typ)
+		var buf bytes.Buffer
+		SetOutput(&buf)
+
+		var calls []string
+		fatalHandlers = nil
+		osExit = func(code int) {
+			calls = append(calls, "exit")
+			assert.Equal(t, 1, code)
+		}
+		OnFatal(func() { calls = append(calls, "first") })
+		OnFatal(func() { panic("ignored") })
+		OnFatal(func() { calls = append(calls, "second") })
+
+		Fatal("fatal %d", 1)
+		assert.Equal(t, []string{"first", "second", "exit"}, calls)
+		assert.Contains(t, buf.String(), "FATAL")
+		assert.Contains(t, buf.String(), "fatal_test.go")
+		assert.Contains(t, buf.String(), ": fatal 1\n")
+
+		calls = nil
+		CtxFatal(context.Background(), "fatal %d", 2)
+		assert.Equal(t, []string{"first", "second", "exit"}, calls)
+		assert.Contains(t, buf.String(), ": fatal 2\n")
+	}
+}
+
+func TestFatalFilteredOut(t *testing.T) {
+	prevHandlers, prevExit := fatalHandlers, osExit
+	defer func() {
+		fatalHandlers, osExit = prevHandlers, prevExit
+	}()
+	fatalHandlers = nil
+	exits := 0
+	osExit = func(int) { exits++ }
+
+	// a fatal line filtered out by the level is neither written nor exits
+	var buf bytes.Buffer
+	z := useLogger(t, LoggerTypeZerolog).FullLogger.(*zerologLogger)
+	z.SetOutput(&buf)
+	z.SetLevel(klog.LevelFatal + 1)
+	z.Fatalf("filtered %d", 1)
+	z.Fatal("filtered")
+	assert.Zero(t, exits)
+	assert.Empty(t, buf.String())
+
+	z.SetLevel(klog.LevelFatal)
+	z.Fatalf("emitted %d", 1)
+	assert.Equal(t, 1, exits)
+	assert.Contains(t, buf.String(), "emitted 1")
+}
+
+func TestOnFatalTimeout(t *testing.T) {
+	prevHandlers, prevExit := fatalHandlers, osExit
+	defer func() {
+		fatalHandlers, osExit = prevHandlers, prevExit
+		SetFatalTimeout(defaultFatalTimeout)
+	}()
+
+	release := make(chan struct{})
+	defer close(release)
+	fatalHandlers = nil
+	OnFatal(func() { <-release })
+	exited := false
+	osExit = func(int) { exited = true }
+	SetFatalTimeout(10 * time.Millisecond)
+
+	exitFatal(1)
+	assert.True(t, exited)
+}
@@ -0,0 +1,51 @@
+package log
+
+import "sync/atomic"
+
+// fieldNames holds the names the trace id and the custom fields are emitted under
+type fieldNames struct {
+	traceID string
+	custom  string
+}
+
+// FieldNameOption renames an emitted field, see SetFieldNames
+type FieldNameOption func(names *fieldNames)
+
+// WithTraceIDName emits the trace id under name instead of trace_id: the key of the FormatLogfmt
+// layout and of the fields handed to the hooks
+func WithTraceIDName(name string) FieldNameOption {
+	return func(names *fieldNames) {
+		names.traceID = name
+	}
+}
+
+// WithCustomFieldsName emits the custom fields under name instead of ctx_extra_data: the key of
+// the object in the zerolog JSON events
+func WithCustomFieldsName(name string) FieldNameOption {
+	return func(names *fieldNames) {
+		names.custom = name
+	}
+}
+
+var currentFieldNames atomic.Pointer[fieldNames]
+
+// SetFieldNames renames the emitted fields to match an existing schema, e.g.
+//
+//	log.SetFieldNames(log.WithTraceIDName("traceId"), log.WithCustomFieldsName("fields"))
+//
+// Fields without an option keep their default name, calling it without options restores them all.
+// The trace id is still read from the trace_id key of the context and of logrus entries.
+func SetFieldNames(opts ...FieldNameOption) {
+	names := &fieldNames{traceID: TraceIDKey, custom: CustomFieldsKey}
+	for _, opt := range opts {
+		opt(names)
+	}
+	currentFieldNames.Store(names)
+}
+
+func getFieldNames() *fieldNames {
+	if names := currentFieldNames.Load(); names != nil {
+		return names
+	}
+	return &fieldNames{traceID: TraceIDKey, custom: CustomFieldsKey}
+}
@@ -0,0 +1,45 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetFieldNames(t *testing.T) {
+	SetFieldNames(WithTraceIDName("traceId"), WithCustomFieldsName("fields"))
+	defer SetFieldNames()
+
+	out := formatLogfmt("t", "INFO", "1", "2", "abc", "a.go:3", []byte("{}"), "done")
+	assert.Equal(t, "time=t level=info pid=1 gid=2 traceId=abc caller=a.go:3 msg=done\n", out)
+
+	// the zerolog events carry the custom fields under the new name, the writer still renders them
+	var raw bytes.Buffer
+	zlog := zerolog.New(&raw).Hook(customFieldsHook{})
+	zlog.Info().Ctx(AppendLogKv(t.Context(), "user", "42")).Msg("raw")
+	assert.Contains(t, raw.String(), `"fields":{"user":"42"}`)
+
+	traceIDs := make(chan string, 1)
+	t.Cleanup(addHook(func(level Level, msg string, fields map[string]string) {
+		if msg == "renamed" {
+			traceIDs <- fields["traceId"]
+		}
+	}))
+	var buf bytes.Buffer
+	w := newCustomWriter(&buf)
+	_, err := w.Write([]byte(`{"level":"info","trace_id":"abc","fields":{"user":"42"},"message":"renamed"}`))
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), ` abc - {"user":"42"} : renamed`)
+	select {
+	case traceID := <-traceIDs:
+		assert.Equal(t, "abc", traceID)
+	case <-time.After(time.Second):
+		t.Fatal("hook not called")
+	}
+
+	SetFieldNames()
+	assert.Equal(t, &fieldNames{traceID: TraceIDKey, custom: CustomFieldsKey}, getFieldNames())
+}
@@ -4,32 +4,56 @@ import "github.com/natefinch/lumberjack"
 
 // LogfileOption is the only way to config log file option.
 type LogfileOption interface {
-	apply(config *lumberjack.Logger)
+	apply(config *logfileConfig)
 }
 
-type logFileOption func(config *lumberjack.Logger)
+// logfileConfig holds the lumberjack roller and the options lumberjack can't express
+type logfileConfig struct {
+	*lumberjack.Logger
+	dailyRotate bool
+	stdout      bool
+}
+
+type logFileOption func(config *logfileConfig)
 
-func (fo logFileOption) apply(config *lumberjack.Logger) {
+func (fo logFileOption) apply(config *logfileConfig) {
 	fo(config)
 }
 
 // WithMaxSize set log file's max size, MB
 func WithMaxSize(size int) LogfileOption {
-	return logFileOption(func(config *lumberjack.Logger) {
+	return logFileOption(func(config *logfileConfig) {
 		config.MaxSize = size
 	})
 }
 
 // WithMaxBackups set maximum number of expired files to keep
 func WithMaxBackups(backups int) LogfileOption {
-	return logFileOption(func(config *lumberjack.Logger) {
+	return logFileOption(func(config *logfileConfig) {
 		config.MaxBackups = backups
 	})
 }
 
 // WithMaxAge set maximum days to keep expired files
 func WithMaxAge(age int) LogfileOption {
-	return logFileOption(func(config *lumberjack.Logger) {
+	return logFileOption(func(config *logfileConfig) {
 		config.MaxAge = age
 	})
 }
+
+// WithDailyRotate rolls over to a new file at local midnight, the file name carries the date,
+// e.g. app.log is written as app-2006-01-02.log. Size based rotation still applies within a day.
+// MaxAge also removes the files of the days older than it, checked when the day changes, while
+// MaxBackups only applies to the backups of the current day's file.
+func WithDailyRotate() LogfileOption {
+	return logFileOption(func(config *logfileConfig) {
+		config.dailyRotate = true
+	})
+}
+
+// WithStdout controls whether SetLogFile also copies the logs to stdout, enabled by default.
+func WithStdout(enable bool) LogfileOption {
+	return logFileOption(func(config *logfileConfig) {
+		config.stdout = enable
+	})
+}
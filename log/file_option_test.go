@@ -0,0 +1,35 @@
+package log
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetLogFileWithoutStdout(t *testing.T) {
+	r, w, err := os.Pipe()
+	assert.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() {
+		os.Stdout = stdout
+		SetOutput(os.Stdout)
+	}()
+
+	fileName := filepath.Join(t.TempDir(), "app.log")
+	SetLogFile(fileName, WithStdout(false))
+	Info("only in file")
+
+	os.Stdout = stdout
+	assert.NoError(t, w.Close())
+	out, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Empty(t, out)
+
+	content, err := os.ReadFile(fileName)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "only in file")
+}
@@ -5,10 +5,10 @@ package log
 
 import (
 	"fmt"
+	"io"
 	"runtime"
 	"strings"
 
-	"github.com/bytedance/sonic"
 	"github.com/sirupsen/logrus"
 )
 
@@ -20,25 +20,58 @@ const (
 
 var LevelStr = [7]string{}
 
+// noticeLevelStr is the level printed for notice lines, see noticeDataKey
+var noticeLevelStr = padLevel(strings.ToUpper(levelNoticeValue))
+
+// noticeDataKey marks the logrus entries of notice lines, logrus has no notice level so they're warn lines
+const noticeDataKey = "_notice"
+
 func init() {
 	for _, l := range logrus.AllLevels {
-		level := strings.ToUpper(l.String())
-		padding := strings.Repeat(" ", levenLen-len(level))
-		LevelStr[l] = padding + level
+		LevelStr[l] = padLevel(strings.ToUpper(l.String()))
+	}
+}
+
+func padLevel(level string) string {
+	return strings.Repeat(" ", levenLen-len(level)) + level
+}
+
+// isLogrusNotice reports whether entry is a notice line, see noticeDataKey
+func isLogrusNotice(entry *logrus.Entry) bool {
+	notice, _ := entry.Data[noticeDataKey].(bool)
+	return notice
+}
+
+// logrusLevelName returns the lower case level name of entry, "notice" for notice lines
+func logrusLevelName(entry *logrus.Entry) string {
+	if isLogrusNotice(entry) {
+		return levelNoticeValue
 	}
+	return entry.Level.String()
 }
 
 // Formatter implements logrus.Formatter interface.
 type Formatter struct {
 }
 
+// CustomFieldsKey is the default field name the custom fields are emitted under, see SetFieldNames
 const CustomFieldsKey = "ctx_extra_data"
+
+// TraceIDKey is the default field name the trace id is emitted under, see SetFieldNames
 const TraceIDKey = "trace_id"
 
+// marshalCustomFields marshals the custom fields with their keys sorted
+func marshalCustomFields(custom any) ([]byte, error) {
+	return getJSONCodec().Marshal(custom)
+}
+
 // Format building log message.
 func (f *Formatter) Format(entry *logrus.Entry) ([]byte, error) {
 	logTime := entry.Time.Format(defaultTimestampFormat)
 	level := LevelStr[entry.Level]
+	if isLogrusNotice(entry) {
+		level = noticeLevelStr
+	}
 
 	traceId := entry.Data[TraceIDKey]
 	if traceId == nil {
@@ -53,8 +86,11 @@ func (f *Formatter) Format(entry *logrus.Entry) ([]byte, error) {
 	if entry.Context == nil {
 		depth = 9
 	}
-	_, file, line, _ := runtime.Caller(depth)
-	caller := fmt.Sprintf("%v:%v", file, line)
+	caller := placeholder
+	if callerEnabled() {
+		pc, file, line, _ := runtime.Caller(depth)
+		caller = formatCaller(pc, file, line)
+	}
 
 	msg := entry.Message
 	pid := GetPID()
@@ -62,15 +98,24 @@ func (f *Formatter) Format(entry *logrus.Entry) ([]byte, error) {
 	custom := "{}"
 	var customMap map[string]string
 	if entry.Context != nil {
-		if m, ok := entry.Context.Value(CustomFieldsKey).(map[string]string); ok {
-			customMap = m
-		}
+		customMap = GetAllCustomFields(entry.Context)
 	}
-	if customMap != nil {
-		bytes, _ := sonic.Marshal(customMap)
+	if customValue := customFieldsValue(entry.Context, withGlobalFields(withOperationField(entry.Context, customMap))); customValue != nil {
+		bytes, _ := marshalCustomFields(customValue)
 		custom = string(bytes)
 	}
-	// time, level, pid, thread id, trace_id, file_loc, :, context info(opt), msg(opt)
-	output := fmt.Sprintf("%v %v %v %v %v %v %v : %v\n", logTime, level, pid, gid, traceId, caller, custom, msg)
+	var output string
+	if getOutputFormat() == FormatLogfmt {
+		output = formatLogfmt(logTime, level, pid, gid, fmt.Sprint(traceId), caller, []byte(custom), msg)
+	} else {
+		// time, level, pid, thread id, trace_id, file_loc, :, context info(opt), msg(opt)
+		output = fmt.Sprintf("%v %v %v %v %v %v %v : %v\n", logTime, level, pid, gid, traceId, caller, truncateCustom(custom), truncateMessage(msg))
+	}
+	// logrus writes the returned line to its single output, the lines of the error output are written
+	// here instead, still under the lock logrus holds while formatting and writing
+	if w := outputFor(nil, logrusLevelName(entry)); w != nil {
+		_, err := io.WriteString(w, output)
+		return nil, err
+	}
 	return []byte(output), nil
 }
@@ -0,0 +1,26 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCustomFieldsSorted(t *testing.T) {
+	fields := map[string]string{"zeta": "1", "alpha": "2", "mid": "3", "beta": "4", "omega": "5"}
+	for _, typ := range []LoggerType{LoggerTypeZerolog, LoggerTypeLogrus} {
+		useLogger(t, typ)
+		var buf bytes.Buffer
+		SetOutput(&buf)
+
+		for i := 0; i < 20; i++ {
+			CtxInfo(AppendLogExtras(context.Background(), fields), "same")
+		}
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			assert.Contains(t, line, `{"alpha":"2","beta":"4","mid":"3","omega":"5","zeta":"1"} : same`)
+		}
+	}
+}
@@ -14,6 +14,8 @@ func (hl *HLogger) SetLevel(level hlog.Level) {
 		lv = klog.LevelDebug
 	case hlog.LevelInfo:
 		lv = klog.LevelInfo
+	case hlog.LevelNotice:
+		lv = klog.LevelNotice
 	case hlog.LevelWarn:
 		lv = klog.LevelWarn
 	case hlog.LevelError:
@@ -27,7 +29,7 @@ func (hl *HLogger) SetLevel(level hlog.Level) {
 }
 
 func WithKitex() {
-	klog.SetLogger(logger)
+	klog.SetLogger(GetLogger())
 }
 
 type HLogger struct {
@@ -36,7 +38,7 @@ type HLogger struct {
 
 func WithHertz() {
 	hl := &HLogger{
-		Logger: logger,
+		Logger: GetLogger(),
 	}
 	hlog.SetLogger(hl)
 }
@@ -0,0 +1,39 @@
+package log
+
+import "sync/atomic"
+
+var globalFields atomic.Pointer[map[string]string]
+
+// SetGlobalFields sets fields such as service, env and version that are added to the custom
+// fields of every log line. Fields of the context take precedence over them.
+func SetGlobalFields(fields map[string]string) {
+	copied := make(map[string]string, len(fields))
+	for k, v := range fields {
+		copied[k] = v
+	}
+	globalFields.Store(&copied)
+}
+
+// withGlobalFields merges the global fields and the host field (see SetHostField) under fields,
+// returning fields itself when there are none
+func withGlobalFields(fields map[string]string) map[string]string {
+	globals := globalFields.Load()
+	host, withHost := getHostField()
+	if (globals == nil || len(*globals) == 0) && !withHost {
+		return fields
+	}
+
+	merged := make(map[string]string, len(fields)+2)
+	if withHost {
+		merged[HostKey] = host
+	}
+	if globals != nil {
+		for k, v := range *globals {
+			merged[k] = v
+		}
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return merged
+}
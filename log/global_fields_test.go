@@ -0,0 +1,26 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetGlobalFields(t *testing.T) {
+	SetGlobalFields(map[string]string{"service": "kit", "env": "test"})
+	defer SetGlobalFields(nil)
+
+	var buf bytes.Buffer
+	zlog := zerolog.New(newCustomWriter(&buf)).Hook(customFieldsHook{})
+
+	zlog.Info().Msg("no ctx")
+	assert.Contains(t, buf.String(), `{"env":"test","service":"kit"} : no ctx`)
+
+	buf.Reset()
+	ctx := AppendLogKv(context.Background(), "env", "canary")
+	zlog.Info().Ctx(ctx).Msg("with ctx")
+	assert.Contains(t, buf.String(), `{"env":"canary","service":"kit"} : with ctx`)
+}
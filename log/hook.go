@@ -0,0 +1,171 @@
+package log
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// HookFunc receives every emitted log line. fields holds the custom fields of the
+// context plus trace_id when present, it must not be modified.
+type HookFunc func(level Level, msg string, fields map[string]string)
+
+const hookQueueSize = 1024
+
+// registeredHook is a hook with the id addHook removes it by
+type registeredHook struct {
+	id uint64
+	fn HookFunc
+}
+
+type hookEntry struct {
+	level  Level
+	msg    string
+	fields map[string]string
+}
+
+var (
+	hooksMu   sync.RWMutex
+	hooks     []registeredHook
+	hookSeq   uint64
+	hookQueue = make(chan hookEntry, hookQueueSize)
+	hookOnce  sync.Once
+	// hookPending counts the queued entries not yet delivered, see flushHooks
+	hookPending atomic.Int64
+)
+
+// AddHook registers fn to be called for every log line, e.g. to forward errors to an
+// alerting webhook. Hooks run on a background goroutine so they never block logging;
+// when they can't keep up, entries are dropped.
+func AddHook(fn HookFunc) {
+	if fn == nil {
+		return
+	}
+	addHook(fn)
+}
+
+// addHook registers fn and returns the func unregistering it
+func addHook(fn HookFunc) (remove func()) {
+	hooksMu.Lock()
+	hookSeq++
+	id := hookSeq
+	hooks = append(hooks, registeredHook{id: id, fn: fn})
+	hooksMu.Unlock()
+
+	hookOnce.Do(func() {
+		go runHooks()
+	})
+
+	return func() {
+		hooksMu.Lock()
+		defer hooksMu.Unlock()
+		// runHooks may be iterating the current slice, removal builds a new one
+		kept := make([]registeredHook, 0, len(hooks))
+		for _, h := range hooks {
+			if h.id != id {
+				kept = append(kept, h)
+			}
+		}
+		hooks = kept
+	}
+}
+
+func hasHooks() bool {
+	hooksMu.RLock()
+	defer hooksMu.RUnlock()
+	return len(hooks) > 0
+}
+
+// fireHooks hands the entry to the hook goroutine without blocking
+func fireHooks(level Level, msg string, fields map[string]string) {
+	hookPending.Add(1)
+	select {
+	case hookQueue <- hookEntry{level: level, msg: msg, fields: fields}:
+	default:
+		hookPending.Add(-1)
+	}
+}
+
+// flushHooks waits until the queued entries are delivered
+func flushHooks() {
+	for hookPending.Load() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func runHooks() {
+	for entry := range hookQueue {
+		hooksMu.RLock()
+		fns := hooks
+		hooksMu.RUnlock()
+
+		for _, h := range fns {
+			callHook(h.fn, entry)
+		}
+		hookPending.Add(-1)
+	}
+}
+
+func callHook(fn HookFunc, entry hookEntry) {
+	defer func() {
+		_ = recover()
+	}()
+	fn(entry.level, entry.msg, entry.fields)
+}
+
+// parseLevel converts zerolog/logrus level names to Level
+func parseLevel(levelStr string) Level {
+	switch levelStr {
+	case "trace":
+		return LevelTrace
+	case "debug":
+		return LevelDebug
+	case "info":
+		return LevelInfo
+	case levelNoticeValue:
+		return LevelNotice
+	case "warn", "warning":
+		return LevelWarn
+	case "error":
+		return LevelError
+	case "fatal", "panic":
+		return LevelFatal
+	default:
+		return LevelInfo
+	}
+}
+
+// logrusHook forwards logrus entries to the registered hooks
+type logrusHook struct{}
+
+var _ logrus.Hook = logrusHook{}
+
+func (logrusHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (logrusHook) Fire(entry *logrus.Entry) error {
+	if !hasHooks() {
+		return nil
+	}
+
+	var custom map[string]string
+	if entry.Context != nil {
+		custom = GetAllCustomFields(entry.Context)
+	}
+	fields := make(map[string]string)
+	for k, v := range withGlobalFields(withOperationField(entry.Context, custom)) {
+		fields[k] = v
+	}
+	for k, v := range numericFields(entry.Context) {
+		fields[k] = strconv.FormatInt(v, 10)
+	}
+	if traceId, ok := entry.Data[TraceIDKey]; ok {
+		fields[getFieldNames().traceID] = getString(traceId)
+	}
+	fireHooks(parseLevel(logrusLevelName(entry)), entry.Message, fields)
+	return nil
+}
@@ -0,0 +1,35 @@
+package log
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddHook(t *testing.T) {
+	type entry struct {
+		level  Level
+		msg    string
+		fields map[string]string
+	}
+	ch := make(chan entry, 16)
+	AddHook(func(level Level, msg string, fields map[string]string) {
+		if level >= LevelError {
+			ch <- entry{level: level, msg: msg, fields: fields}
+		}
+	})
+
+	ctx := AppendLogKv(context.Background(), "order_id", "42")
+	CtxError(ctx, "pay failed %d", 1)
+
+	select {
+	case e := <-ch:
+		assert.Equal(t, LevelError, e.level)
+		assert.Equal(t, "pay failed 1", e.msg)
+		assert.Equal(t, "42", e.fields["order_id"])
+	case <-time.After(time.Second):
+		t.Fatal("hook not called")
+	}
+}
@@ -0,0 +1,46 @@
+package log
+
+import (
+	"os"
+	"sync/atomic"
+)
+
+// HostKey is the custom field SetHostField adds to every log line
+const HostKey = "host"
+
+var hostField atomic.Pointer[string]
+
+// SetHostField adds the host custom field, the value of os.Hostname, to every log line when enabled.
+// The name is looked up once here rather than for every line.
+func SetHostField(enabled bool) {
+	SetHostFieldFromEnv(enabled, "")
+}
+
+// SetHostFieldFromEnv is SetHostField taking the host from the env var name, e.g. POD_NAME set through
+// the downward API in k8s. It falls back to os.Hostname when name is empty or the var isn't set
+func SetHostFieldFromEnv(enabled bool, name string) {
+	if !enabled {
+		hostField.Store(nil)
+		return
+	}
+	host := ""
+	if name != "" {
+		host = os.Getenv(name)
+	}
+	if host == "" {
+		host, _ = os.Hostname()
+	}
+	if host == "" {
+		host = placeholder
+	}
+	hostField.Store(&host)
+}
+
+// getHostField returns the host and whether SetHostField is enabled
+func getHostField() (string, bool) {
+	host := hostField.Load()
+	if host == nil {
+		return "", false
+	}
+	return *host, true
+}
@@ -0,0 +1,35 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetHostField(t *testing.T) {
+	defer SetHostField(false)
+
+	var buf bytes.Buffer
+	zlog := zerolog.New(newCustomWriter(&buf)).Hook(customFieldsHook{})
+
+	SetHostField(true)
+	hostname, _ := os.Hostname()
+	zlog.Info().Msg("hostname")
+	assert.Contains(t, buf.String(), `{"host":"`+hostname+`"} : hostname`)
+
+	buf.Reset()
+	t.Setenv("POD_NAME", "api-7d9f")
+	SetHostFieldFromEnv(true, "POD_NAME")
+	ctx := AppendLogKv(context.Background(), "user", "42")
+	zlog.Info().Ctx(ctx).Msg("pod")
+	assert.Contains(t, buf.String(), `{"host":"api-7d9f","user":"42"} : pod`)
+
+	buf.Reset()
+	SetHostField(false)
+	zlog.Info().Msg("off")
+	assert.Contains(t, buf.String(), `{} : off`)
+}
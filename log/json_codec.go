@@ -0,0 +1,64 @@
+package log
+
+import (
+	"encoding/json"
+	"sync/atomic"
+
+	"github.com/bytedance/sonic"
+)
+
+// JSONCodec encodes the custom fields and decodes the zerolog lines, see SetJSONCodec
+type JSONCodec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+var (
+	// SonicCodec is backed by bytedance/sonic with sorted map keys, the default where sonic supports the platform
+	SonicCodec JSONCodec = sonic.Config{SortMapKeys: true}.Froze()
+	// StdCodec is backed by encoding/json, the default where sonic would fall back to it anyway
+	StdCodec JSONCodec = stdCodec{}
+)
+
+type stdCodec struct{}
+
+func (stdCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (stdCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// codecHolder keeps the type stored in jsonCodec the same whatever the codec
+type codecHolder struct {
+	JSONCodec
+}
+
+var jsonCodec atomic.Value
+
+func init() {
+	SetJSONCodec(nil)
+}
+
+// defaultJSONCodec is SonicCodec when sonic runs natively on this platform and StdCodec otherwise
+func defaultJSONCodec() JSONCodec {
+	if sonic.APIKind == sonic.UseSonicJSON {
+		return SonicCodec
+	}
+	return StdCodec
+}
+
+// SetJSONCodec sets the codec the log package uses for JSON, e.g. StdCodec on CPUs sonic can't run on.
+// A nil codec restores the default. Custom fields should marshal with sorted map keys,
+// so the same fields always print the same.
+func SetJSONCodec(c JSONCodec) {
+	if c == nil {
+		c = defaultJSONCodec()
+	}
+	jsonCodec.Store(codecHolder{c})
+}
+
+func getJSONCodec() JSONCodec {
+	return jsonCodec.Load().(codecHolder).JSONCodec
+}
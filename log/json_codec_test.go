@@ -0,0 +1,42 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingCodec struct {
+	JSONCodec
+	marshals int
+}
+
+func (c *countingCodec) Marshal(v any) ([]byte, error) {
+	c.marshals++
+	return c.JSONCodec.Marshal(v)
+}
+
+func TestSetJSONCodec(t *testing.T) {
+	defer func() {
+		SetJSONCodec(nil)
+	}()
+
+	codec := &countingCodec{JSONCodec: StdCodec}
+	SetJSONCodec(codec)
+	fields := map[string]string{"zeta": "1", "alpha": "2"}
+	for _, typ := range []LoggerType{LoggerTypeZerolog, LoggerTypeLogrus} {
+		useLogger(t, typ)
+		var buf bytes.Buffer
+		SetOutput(&buf)
+
+		CtxInfo(AppendLogExtras(context.Background(), fields), "std")
+		assert.Contains(t, strings.TrimSpace(buf.String()), `{"alpha":"2","zeta":"1"} : std`)
+	}
+	assert.Positive(t, codec.marshals)
+
+	SetJSONCodec(nil)
+	assert.Equal(t, defaultJSONCodec(), getJSONCodec())
+}
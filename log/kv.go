@@ -0,0 +1,72 @@
+package log
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mbeoliero/kit/utils/typex"
+)
+
+// kvErrorKey is the custom field added when a kv list has an odd number of elements
+const kvErrorKey = "log_kv_error"
+
+// DebugKV logs msg at debug level with kv, alternating keys and values, as custom fields.
+func DebugKV(msg string, kv ...any) {
+	getDefaultLogger().CtxDebugf(withKV(context.Background(), kv), "%s", msg)
+}
+
+// InfoKV logs msg at info level with kv, alternating keys and values, as custom fields.
+// e.g. InfoKV("user updated", "user_id", 42, "action", "rename")
+func InfoKV(msg string, kv ...any) {
+	getDefaultLogger().CtxInfof(withKV(context.Background(), kv), "%s", msg)
+}
+
+// WarnKV logs msg at warn level with kv, alternating keys and values, as custom fields.
+func WarnKV(msg string, kv ...any) {
+	getDefaultLogger().CtxWarnf(withKV(context.Background(), kv), "%s", msg)
+}
+
+// ErrorKV logs msg at error level with kv, alternating keys and values, as custom fields.
+func ErrorKV(msg string, kv ...any) {
+	getDefaultLogger().CtxErrorf(withKV(context.Background(), kv), "%s", msg)
+}
+
+// CtxDebugKV is DebugKV with ctx, kv is merged over the custom fields of ctx.
+func CtxDebugKV(ctx context.Context, msg string, kv ...any) {
+	getDefaultLogger().CtxDebugf(withKV(ctx, kv), "%s", msg)
+}
+
+// CtxInfoKV is InfoKV with ctx, kv is merged over the custom fields of ctx.
+func CtxInfoKV(ctx context.Context, msg string, kv ...any) {
+	getDefaultLogger().CtxInfof(withKV(ctx, kv), "%s", msg)
+}
+
+// CtxWarnKV is WarnKV with ctx, kv is merged over the custom fields of ctx.
+func CtxWarnKV(ctx context.Context, msg string, kv ...any) {
+	getDefaultLogger().CtxWarnf(withKV(ctx, kv), "%s", msg)
+}
+
+// CtxErrorKV is ErrorKV with ctx, kv is merged over the custom fields of ctx.
+func CtxErrorKV(ctx context.Context, msg string, kv ...any) {
+	getDefaultLogger().CtxErrorf(withKV(ctx, kv), "%s", msg)
+}
+
+// withKV merges the kv pairs into the custom fields of ctx. A trailing key without value
+// is kept with an empty value and flagged with kvErrorKey.
+func withKV(ctx context.Context, kv []any) context.Context {
+	if len(kv) == 0 {
+		return ctx
+	}
+
+	fields := make(map[string]string, len(kv)/2+1)
+	for i := 0; i < len(kv); i += 2 {
+		key := fmt.Sprint(kv[i])
+		if i+1 >= len(kv) {
+			fields[key] = ""
+			fields[kvErrorKey] = fmt.Sprintf("odd number of kv arguments, %q has no value", key)
+			break
+		}
+		fields[key] = typex.ToString(kv[i+1])
+	}
+	return AppendLogExtras(ctx, fields)
+}
@@ -0,0 +1,22 @@
+package log
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithKV(t *testing.T) {
+	ctx := AppendLogKv(context.Background(), "trace", "a")
+
+	fields := GetAllCustomFields(withKV(ctx, []any{"user_id", 42, "ok", true}))
+	assert.Equal(t, map[string]string{"trace": "a", "user_id": "42", "ok": "true"}, fields)
+
+	fields = GetAllCustomFields(withKV(ctx, []any{"user_id", 42, "dangling"}))
+	assert.Equal(t, "42", fields["user_id"])
+	assert.Equal(t, "", fields["dangling"])
+	assert.Contains(t, fields[kvErrorKey], "dangling")
+
+	assert.Equal(t, ctx, withKV(ctx, nil))
+}
@@ -0,0 +1,89 @@
+package log
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// OutputFormat is the layout of the log lines
+type OutputFormat int32
+
+const (
+	// FormatText is the positional layout: time level pid gid trace_id caller custom : msg, the default
+	FormatText OutputFormat = iota
+	// FormatLogfmt is the key=value layout: time=... level=... pid=... gid=... trace_id=... caller=... msg=...
+	// followed by the custom fields as k=v pairs, sorted by key
+	FormatLogfmt
+)
+
+var outputFormat atomic.Int32
+
+// SetOutputFormat sets the layout of the log lines of both zerolog and logrus.
+func SetOutputFormat(f OutputFormat) {
+	outputFormat.Store(int32(f))
+}
+
+func getOutputFormat() OutputFormat {
+	return OutputFormat(outputFormat.Load())
+}
+
+// formatLogfmt renders a line in the FormatLogfmt layout, custom is the JSON object of the custom fields
+func formatLogfmt(logTime, level, pid, gid, traceID, caller string, custom []byte, msg string) string {
+	var sb strings.Builder
+	writeLogfmtValue(&sb, "time", logTime)
+	writeLogfmtPair(&sb, "level", strings.ToLower(strings.TrimSpace(level)))
+	writeLogfmtPair(&sb, "pid", pid)
+	writeLogfmtPair(&sb, "gid", gid)
+	writeLogfmtPair(&sb, getFieldNames().traceID, traceID)
+	writeLogfmtPair(&sb, "caller", caller)
+	writeLogfmtPair(&sb, "msg", truncateMessage(msg))
+
+	var fields map[string]interface{}
+	if len(custom) > 0 && getJSONCodec().Unmarshal(custom, &fields) == nil && len(fields) > 0 {
+		keys := make([]string, 0, len(fields))
+		for k := range fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		var cb strings.Builder
+		for _, k := range keys {
+			writeLogfmtPair(&cb, k, getString(fields[k]))
+		}
+		sb.WriteString(truncateCustom(cb.String()))
+	}
+	sb.WriteByte('\n')
+	return sb.String()
+}
+
+// writeLogfmtPair writes " key=value"
+func writeLogfmtPair(sb *strings.Builder, key, value string) {
+	sb.WriteByte(' ')
+	writeLogfmtValue(sb, key, value)
+}
+
+// writeLogfmtValue writes "key=value", value is quoted when needed
+func writeLogfmtValue(sb *strings.Builder, key, value string) {
+	sb.WriteString(key)
+	sb.WriteByte('=')
+	if needsLogfmtQuote(value) {
+		sb.WriteString(strconv.Quote(value))
+		return
+	}
+	sb.WriteString(value)
+}
+
+// needsLogfmtQuote reports whether value is empty or holds a space, '=', '"' or a control character
+func needsLogfmtQuote(value string) bool {
+	if value == "" {
+		return true
+	}
+	for _, r := range value {
+		if r <= ' ' || r == '=' || r == '"' || r == 0x7f {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatLogfmt(t *testing.T) {
+	out := formatLogfmt("2024-01-02 03:04:05.000", "   INFO", "1", "2", "-", "a.go:3",
+		[]byte(`{"user":"bob smith","id":"42","empty":""}`), `say "hi"`)
+	assert.Equal(t, `time="2024-01-02 03:04:05.000" level=info pid=1 gid=2 trace_id=- caller=a.go:3 msg="say \"hi\"" empty="" id=42 user="bob smith"`+"\n", out)
+
+	out = formatLogfmt("t", "WARN", "1", "2", "-", "a.go:3", []byte("{}"), "done")
+	assert.Equal(t, "time=t level=warn pid=1 gid=2 trace_id=- caller=a.go:3 msg=done\n", out)
+}
+
+func TestSetOutputFormat(t *testing.T) {
+	SetOutputFormat(FormatLogfmt)
+	defer func() {
+		SetOutputFormat(FormatText)
+	}()
+
+	for _, typ := range []LoggerType{LoggerTypeZerolog, LoggerTypeLogrus} {
+		useLogger(t, typ)
+		var buf bytes.Buffer
+		SetOutput(&buf)
+
+		CtxInfo(AppendLogKv(context.Background(), "user_id", "42"), "user %s", "updated")
+
+		out := buf.String()
+		assert.Regexp(t, regexp.MustCompile(`^time="[^"]+" level=info pid=\d+ gid=\d+ trace_id=\S+ caller=\S*logfmt_test\.go:\d+ msg="user updated" user_id=42\n$`), out)
+	}
+}
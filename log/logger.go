@@ -4,33 +4,49 @@ import (
 	"context"
 	"io"
 	"os"
+	"sync/atomic"
 
 	"github.com/cloudwego/kitex/pkg/klog"
 	kitexlogrus "github.com/kitex-contrib/obs-opentelemetry/logging/logrus"
 	kitexzerolog "github.com/kitex-contrib/obs-opentelemetry/logging/zerolog"
 	"github.com/natefinch/lumberjack"
 	"github.com/rs/zerolog"
+	"github.com/sirupsen/logrus"
 )
 
 var (
-	logger        *Logger
-	defaultLogger klog.FullLogger
-	logLevel      Level
-	customOut     *customWriter
+	// logger and defaultLogger are replaced by SwitchLogger and SetLogger while other goroutines log
+	logger        atomic.Pointer[Logger]
+	defaultLogger atomic.Pointer[klog.FullLogger]
+	logLevel      atomic.Int64 // Level
+
+	// metricsEnabled records that SetProdEnv enabled the metrics, SwitchLogger enables them on the new logger
+	metricsEnabled atomic.Bool
 )
 
 // Logger wraps different logger implementations
 type Logger struct {
 	klog.FullLogger
 	loggerType LoggerType
+	level      atomic.Int32 // klog.Level
 }
 
 // Set custom format
 func init() {
-	logger = newLogger()
-	logger.SetLevel(klog.LevelDebug)
-	logLevel = LevelDebug
-	defaultLogger = logger
+	l := newLogger()
+	l.SetLevel(klog.LevelDebug)
+	logLevel.Store(int64(LevelDebug))
+	logger.Store(l)
+	setDefaultLogger(l)
+}
+
+// getDefaultLogger returns the logger the package functions log with
+func getDefaultLogger() klog.FullLogger {
+	return *defaultLogger.Load()
+}
+
+func setDefaultLogger(l klog.FullLogger) {
+	defaultLogger.Store(&l)
 }
 
 func newLogger() *Logger {
@@ -55,59 +71,141 @@ func newLogrusLogger() *Logger {
 	logrusLogger := l.Logger()
 	logrusLogger.SetFormatter(&Formatter{})
 	logrusLogger.AddHook(&traceIdHook{})
+	logrusLogger.AddHook(logrusHook{})
+	logrusLogger.ExitFunc = exitFatal
 
 	return lg
 }
 
+// newLogrusNotice returns the entry notice lines are emitted with: logrus has no notice level, they're
+// warn lines marked with noticeDataKey, which the formatter and the hooks read back. The entry always has
+// a context, so the formatter looks for the caller at the depth of the Ctx* calls.
+func newLogrusNotice(lr *kitexlogrus.Logger, ctx context.Context) *logrus.Entry {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return lr.Logger().WithContext(ctx).WithField(noticeDataKey, true)
+}
+
 func newZerologLogger() *Logger {
 	// Create custom writer for formatting
-	customOut = newCustomWriter(os.Stdout)
+	out := newCustomWriter(os.Stdout)
 
 	// Create zerolog logger with proper configuration
-	zlog := zerolog.New(customOut).
+	zlog := zerolog.New(out).
 		With().Timestamp().Logger().
 		Hook(customFieldsHook{})
 
-	// Use CallerWithSkipFrameCount to get correct caller location
-	// Skip 5 frames to get to the actual user code
-	zlog = zlog.With().CallerWithSkipFrameCount(5).Logger()
+	// The caller hook resolves the caller location unless SetCaller(false) is set
+	zlog = zlog.Hook(callerHook{})
 
-	// Create kitex logger wrapper
-	l := kitexzerolog.NewLogger(kitexzerolog.WithLogger(&zlog))
+	// kitex's zerolog wrapper adds its OpenTelemetry hook, the lines are emitted by zerologLogger
+	zl := kitexzerolog.NewLogger(kitexzerolog.WithLogger(&zlog)).Logger()
 
 	lg := &Logger{
-		FullLogger: l,
+		FullLogger: &zerologLogger{zl: zl, out: out},
 		loggerType: LoggerTypeZerolog,
 	}
 
 	return lg
 }
 
+// SetLevel sets the level of the wrapped logger, it's safe to call while other goroutines log.
+func (l *Logger) SetLevel(level klog.Level) {
+	l.level.Store(int32(level))
+	l.FullLogger.SetLevel(level)
+}
+
+// getLevel returns the level set with SetLevel
+func (l *Logger) getLevel() klog.Level {
+	return klog.Level(l.level.Load())
+}
+
+// Noticef logs at notice level. The kitex logrus wrapper downgrades notice to warn,
+// the line is emitted with a "notice" level instead, see newLogrusNotice.
+func (l *Logger) Noticef(format string, v ...interface{}) {
+	switch fl := l.FullLogger.(type) {
+	case *kitexlogrus.Logger:
+		if l.getLevel() <= klog.LevelNotice {
+			newLogrusNotice(fl, nil).Warnf(format, v...)
+		}
+	case *zerologLogger:
+		fl.logf(nil, klog.LevelNotice, format, v...)
+	default:
+		fl.Noticef(format, v...)
+	}
+}
+
+// CtxNoticef logs at notice level with ctx, see Noticef.
+func (l *Logger) CtxNoticef(ctx context.Context, format string, v ...interface{}) {
+	switch fl := l.FullLogger.(type) {
+	case *kitexlogrus.Logger:
+		if l.getLevel() <= klog.LevelNotice {
+			newLogrusNotice(fl, ctx).Warnf(format, v...)
+		}
+	case *zerologLogger:
+		fl.logf(ctx, klog.LevelNotice, format, v...)
+	default:
+		fl.CtxNoticef(ctx, format, v...)
+	}
+}
+
+// Notice logs at notice level, see Noticef.
+func (l *Logger) Notice(v ...interface{}) {
+	switch fl := l.FullLogger.(type) {
+	case *kitexlogrus.Logger:
+		if l.getLevel() <= klog.LevelNotice {
+			newLogrusNotice(fl, nil).Warn(v...)
+		}
+	case *zerologLogger:
+		fl.log(klog.LevelNotice, v...)
+	default:
+		fl.Notice(v...)
+	}
+}
+
 func SetLogger(fullLogger klog.FullLogger) {
-	defaultLogger = fullLogger
+	setDefaultLogger(fullLogger)
 }
 
 func SetProdEnv() {
-	logger.SetLevel(klog.LevelInfo)
-	logLevel = LevelInfo
+	l := GetLogger()
+	l.SetLevel(klog.LevelInfo)
+	logLevel.Store(int64(LevelInfo))
+	l.enableMetrics()
+	metricsEnabled.Store(true)
+	registerMetricRecorder(getMetricRecorder())
+}
 
-	// Enable metrics collection based on logger type
-	switch logger.loggerType {
+// enableMetrics enables the metrics collection based on the logger type
+func (l *Logger) enableMetrics() {
+	switch l.loggerType {
 	case LoggerTypeLogrus:
 		// Add metric hook for logrus
-		if l, ok := logger.FullLogger.(*kitexlogrus.Logger); ok {
-			l.Logger().AddHook(metricHook{})
+		if lr, ok := l.FullLogger.(*kitexlogrus.Logger); ok {
+			lr.Logger().AddHook(metricHook{})
 		}
 	case LoggerTypeZerolog:
 		// Enable metrics for zerolog
-		if customOut != nil {
-			customOut.enableMetrics()
+		if z, ok := l.FullLogger.(*zerologLogger); ok {
+			z.out.enableMetrics()
 		}
 	}
 }
 
+// output returns the writer the logger writes to
+func (l *Logger) output() io.Writer {
+	if z, ok := l.FullLogger.(*zerologLogger); ok {
+		return z.out.output()
+	}
+	if lr, ok := l.FullLogger.(*kitexlogrus.Logger); ok {
+		return lr.Logger().Out
+	}
+	return nil
+}
+
 func GetLogger() *Logger {
-	return logger
+	return logger.Load()
 }
 
 // Level defines the priority of a log message.
@@ -120,37 +218,49 @@ const (
 	LevelTrace Level = iota
 	LevelDebug
 	LevelInfo
+	LevelNotice
 	LevelWarn
 	LevelError
 	LevelFatal
 )
 
 // SetLevel sets the level of logs below which logs will not be output.
-// The default log level is LevelTrace.
-// Note that this method is not concurrent-safe.
+// The default log level is LevelDebug. It's safe to call while other goroutines log.
 func SetLevel(level Level) {
-	var lv klog.Level
+	getDefaultLogger().SetLevel(toKlogLevel(level))
+	logLevel.Store(int64(level))
+}
+
+func toKlogLevel(level Level) klog.Level {
 	switch level {
 	case LevelTrace:
-		lv = klog.LevelTrace
+		return klog.LevelTrace
 	case LevelDebug:
-		lv = klog.LevelDebug
+		return klog.LevelDebug
 	case LevelInfo:
-		lv = klog.LevelInfo
+		return klog.LevelInfo
+	case LevelNotice:
+		return klog.LevelNotice
 	case LevelWarn:
-		lv = klog.LevelWarn
+		return klog.LevelWarn
 	case LevelError:
-		lv = klog.LevelError
+		return klog.LevelError
 	case LevelFatal:
-		lv = klog.LevelFatal
+		return klog.LevelFatal
 	default:
-		lv = klog.LevelWarn
+		return klog.LevelWarn
+	}
+}
+
+// levelEnabled reports whether the default logger emits lines at level
+func levelEnabled(level Level) bool {
+	if l, ok := getDefaultLogger().(*Logger); ok {
+		return toKlogLevel(level) >= l.getLevel()
 	}
-	defaultLogger.SetLevel(lv)
-	logLevel = level
+	return level >= GetLogLevel()
 }
 
-// SetLogFile sets log output to file and stdout.
+// SetLogFile sets log output to file and stdout, use WithStdout(false) to write the file only.
 // Use lumberjack to rolling file.
 func SetLogFile(fileName string, ops ...LogfileOption) {
 	// roller with default params
@@ -162,89 +272,137 @@ func SetLogFile(fileName string, ops ...LogfileOption) {
 		Compress:   true, // Whether rolling logs need to be compressed, use gzip to compress
 	}
 
+	cfg := &logfileConfig{Logger: rollingWriter, stdout: true}
 	for _, op := range ops {
-		op.apply(rollingWriter)
+		op.apply(cfg)
+	}
+
+	var fileWriter io.Writer = rollingWriter
+	if cfg.dailyRotate {
+		fileWriter = newDailyWriter(rollingWriter)
 	}
 
-	mw := io.MultiWriter(rollingWriter, os.Stdout)
-	defaultLogger.SetOutput(mw)
+	if !cfg.stdout {
+		getDefaultLogger().SetOutput(fileWriter)
+		return
+	}
+	mw := io.MultiWriter(fileWriter, os.Stdout)
+	getDefaultLogger().SetOutput(mw)
 }
 
 // SetOutput sets the output of default logger. By default, it is stderr.
 func SetOutput(w io.Writer) {
-	defaultLogger.SetOutput(w)
+	getDefaultLogger().SetOutput(w)
 }
 
-// Fatal calls the default logger's Fatalf method and then os.Exit(1).
+// Fatal calls the default logger's Fatalf method and then os.Exit(1), see OnFatal.
+// Both backends exit: kitex's zerolog wrapper logged Fatalf at error level and carried on,
+// the zerolog backend now logs it at fatal level and exits like CtxFatal, so does Logger.Fatalf.
 func Fatal(format string, v ...interface{}) {
-	defaultLogger.Fatalf(format, v...)
+	getDefaultLogger().Fatalf(format, v...)
 }
 
 // Error calls the default logger's Errorf method.
 func Error(format string, v ...interface{}) {
-	defaultLogger.Errorf(format, v...)
+	if !allowLog(LevelError, format) {
+		return
+	}
+	getDefaultLogger().Errorf(format, v...)
 }
 
 // Warn calls the default logger's Warnf method.
 func Warn(format string, v ...interface{}) {
-	defaultLogger.Warnf(format, v...)
+	if !allowLog(LevelWarn, format) {
+		return
+	}
+	getDefaultLogger().Warnf(format, v...)
 }
 
 // Notice calls the default logger's Noticef method.
 func Notice(format string, v ...interface{}) {
-	defaultLogger.Noticef(format, v...)
+	if !allowLog(LevelNotice, format) {
+		return
+	}
+	getDefaultLogger().Noticef(format, v...)
 }
 
 // Info calls the default logger's Infof method.
 func Info(format string, v ...interface{}) {
-	defaultLogger.Infof(format, v...)
+	if !allowLog(LevelInfo, format) {
+		return
+	}
+	getDefaultLogger().Infof(format, v...)
 }
 
 // Debug calls the default logger's Debugf method.
 func Debug(format string, v ...interface{}) {
-	defaultLogger.Debugf(format, v...)
+	if !allowLog(LevelDebug, format) {
+		return
+	}
+	getDefaultLogger().Debugf(format, v...)
 }
 
 // Trace calls the default logger's Tracef method.
 func Trace(format string, v ...interface{}) {
-	defaultLogger.Tracef(format, v...)
+	if !allowLog(LevelTrace, format) {
+		return
+	}
+	getDefaultLogger().Tracef(format, v...)
 }
 
-// CtxFatal calls the default logger's CtxFatalf method and then os.Exit(1).
+// CtxFatal calls the default logger's CtxFatalf method and then os.Exit(1), see OnFatal.
 func CtxFatal(ctx context.Context, format string, v ...interface{}) {
-	defaultLogger.CtxFatalf(ctx, format, v...)
+	getDefaultLogger().CtxFatalf(ctx, format, v...)
 }
 
 // CtxError calls the default logger's CtxErrorf method.
 func CtxError(ctx context.Context, format string, v ...interface{}) {
-	defaultLogger.CtxErrorf(ctx, format, v...)
+	if !allowLog(LevelError, format) {
+		return
+	}
+	getDefaultLogger().CtxErrorf(ctx, format, v...)
 }
 
 // CtxWarn calls the default logger's CtxWarnf method.
 func CtxWarn(ctx context.Context, format string, v ...interface{}) {
-	defaultLogger.CtxWarnf(ctx, format, v...)
+	if !allowLog(LevelWarn, format) {
+		return
+	}
+	getDefaultLogger().CtxWarnf(ctx, format, v...)
 }
 
 // CtxNotice calls the default logger's CtxNoticef method.
 func CtxNotice(ctx context.Context, format string, v ...interface{}) {
-	defaultLogger.CtxNoticef(ctx, format, v...)
+	if !allowLog(LevelNotice, format) {
+		return
+	}
+	getDefaultLogger().CtxNoticef(ctx, format, v...)
 }
 
 // CtxInfo calls the default logger's CtxInfof method.
 func CtxInfo(ctx context.Context, format string, v ...interface{}) {
-	defaultLogger.CtxInfof(ctx, format, v...)
+	if !allowLog(LevelInfo, format) {
+		return
+	}
+	getDefaultLogger().CtxInfof(ctx, format, v...)
 }
 
 // CtxDebug calls the default logger's CtxDebugf method.
 func CtxDebug(ctx context.Context, format string, v ...interface{}) {
-	defaultLogger.CtxDebugf(ctx, format, v...)
+	if !allowLog(LevelDebug, format) {
+		return
+	}
+	getDefaultLogger().CtxDebugf(ctx, format, v...)
 }
 
 // CtxTrace calls the default logger's CtxTracef method.
 func CtxTrace(ctx context.Context, format string, v ...interface{}) {
-	defaultLogger.CtxTracef(ctx, format, v...)
+	if !allowLog(LevelTrace, format) {
+		return
+	}
+	getDefaultLogger().CtxTracef(ctx, format, v...)
 }
 
 func GetLogLevel() Level {
-	return logLevel
+	return Level(logLevel.Load())
 }
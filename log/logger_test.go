@@ -1,11 +1,16 @@
 package log
 
 import (
+	"bytes"
 	"context"
+	"io"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/cloudwego/kitex/pkg/klog"
 	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
@@ -44,10 +49,7 @@ func TestInfo(t *testing.T) {
 
 func TestInfoWithLogrus(t *testing.T) {
 	// Test with logrus
-	SetLoggerType(LoggerTypeLogrus)
-	logger = newLogger()
-	logger.SetLevel(klog.LevelDebug)
-	defaultLogger = logger
+	useLogger(t, LoggerTypeLogrus).SetLevel(klog.LevelDebug)
 
 	// Initialize OpenTelemetry
 	tp := sdktrace.NewTracerProvider(
@@ -80,3 +82,104 @@ func TestZerolog(t *testing.T) {
 	zlog = zlog.With().CallerWithSkipFrameCount(5).Logger()
 	zlog.Printf("zerolog test with trace ID %s", "1234")
 }
+
+func TestNotice(t *testing.T) {
+	useLogger(t, LoggerTypeZerolog)
+
+	var buf bytes.Buffer
+	SetOutput(&buf)
+
+	SetLevel(LevelNotice)
+	Info("hidden info")
+	CtxNotice(context.Background(), "notice %d", 1)
+	Notice("notice %d", 2)
+	assert.NotContains(t, buf.String(), "hidden info")
+	assert.Contains(t, buf.String(), " NOTICE ")
+	assert.Contains(t, buf.String(), "logger_test.go")
+	assert.Contains(t, buf.String(), ": notice 1\n")
+	assert.Contains(t, buf.String(), ": notice 2\n")
+
+	buf.Reset()
+	SetLevel(LevelWarn)
+	Notice("hidden notice")
+	Warn("shown warn")
+	assert.NotContains(t, buf.String(), "hidden notice")
+	assert.Contains(t, buf.String(), " WARN ")
+}
+
+func TestNoticeLogrus(t *testing.T) {
+	useLogger(t, LoggerTypeLogrus)
+
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	var levels []Level
+	var mu sync.Mutex
+	AddHook(func(level Level, msg string, fields map[string]string) {
+		if strings.HasPrefix(msg, "logrus notice") {
+			mu.Lock()
+			levels = append(levels, level)
+			mu.Unlock()
+		}
+	})
+
+	SetLevel(LevelNotice)
+	Notice("logrus notice %d", 1)
+	CtxNotice(context.Background(), "logrus notice %d", 2)
+	assert.Equal(t, 2, strings.Count(buf.String(), " NOTICE "))
+	assert.NotContains(t, buf.String(), "WARN")
+	assert.Regexp(t, `logger_test\.go:\d+ \{\} : logrus notice 1\n`, buf.String())
+	assert.Regexp(t, `logger_test\.go:\d+ \{\} : logrus notice 2\n`, buf.String())
+	flushHooks()
+	mu.Lock()
+	assert.Equal(t, []Level{LevelNotice, LevelNotice}, levels)
+	mu.Unlock()
+
+	buf.Reset()
+	SetLevel(LevelWarn)
+	Notice("hidden notice")
+	assert.Empty(t, buf.String())
+}
+
+func TestSetLevelConcurrent(t *testing.T) {
+	prevLevel := GetLogLevel()
+	defer func() {
+		logLevel.Store(int64(prevLevel))
+	}()
+
+	for _, typ := range []LoggerType{LoggerTypeZerolog, LoggerTypeLogrus} {
+		useLogger(t, typ)
+		SetOutput(io.Discard)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 200; j++ {
+					Error("concurrent %d", j)
+					Debug("concurrent %d", j)
+				}
+			}()
+		}
+		for _, level := range []Level{LevelDebug, LevelError, LevelInfo, LevelWarn} {
+			SetLevel(level)
+		}
+		wg.Wait()
+	}
+}
+
+// useLogger makes a new logger of type typ the current and default logger until the test ends
+func useLogger(tb testing.TB, typ LoggerType) *Logger {
+	prevLogger, prevDefault, prevType := logger.Load(), defaultLogger.Load(), GetLoggerType()
+	tb.Cleanup(func() {
+		SetLoggerType(prevType)
+		logger.Store(prevLogger)
+		defaultLogger.Store(prevDefault)
+	})
+
+	SetLoggerType(typ)
+	l := newLogger()
+	logger.Store(l)
+	setDefaultLogger(l)
+	return l
+}
@@ -1,17 +1,84 @@
 package log
 
 import (
+	"errors"
+	"sync"
+	"sync/atomic"
+
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/sirupsen/logrus"
 )
 
-var errLogCounter = promauto.NewCounterVec(
-	prometheus.CounterOpts{
-		Name: "go_service_log_error_count",
-	}, []string{"level"},
+// MetricRecorder counts the warn and above log lines once SetProdEnv enabled metrics.
+// level is the level name, upper case with zerolog ("ERROR") and lower case with logrus ("error")
+type MetricRecorder interface {
+	IncLevel(level string)
+}
+
+var metricRecorder atomic.Pointer[MetricRecorder]
+
+// SetMetricRecorder replaces the recorder of the log level metrics, e.g. with an OpenTelemetry
+// backed one. The default recorder is the go_service_log_error_count Prometheus counter,
+// registered on the default registerer as soon as the metrics are enabled, so it's exported
+// before the first warning is logged.
+func SetMetricRecorder(r MetricRecorder) {
+	if r == nil {
+		r = prometheusRecorder{}
+	}
+	metricRecorder.Store(&r)
+	if metricsEnabled.Load() {
+		registerMetricRecorder(r)
+	}
+}
+
+func getMetricRecorder() MetricRecorder {
+	if r := metricRecorder.Load(); r != nil {
+		return *r
+	}
+	return prometheusRecorder{}
+}
+
+var (
+	errLogCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "go_service_log_error_count",
+		}, []string{"level"},
+	)
+	errLogCounterOnce sync.Once
 )
 
+// registerMetricRecorder registers the counter of the default recorder, other recorders are
+// registered by their owner
+func registerMetricRecorder(r MetricRecorder) {
+	if _, ok := r.(prometheusRecorder); ok {
+		registerErrLogCounter()
+	}
+}
+
+// registerErrLogCounter registers errLogCounter on the default registerer once, reusing the
+// counter already registered under the same name if any
+func registerErrLogCounter() {
+	errLogCounterOnce.Do(func() {
+		if err := prometheus.Register(errLogCounter); err != nil {
+			var are prometheus.AlreadyRegisteredError
+			if errors.As(err, &are) {
+				if existing, ok := are.ExistingCollector.(*prometheus.CounterVec); ok {
+					errLogCounter = existing
+				}
+			}
+		}
+	})
+}
+
+// prometheusRecorder is the default MetricRecorder
+type prometheusRecorder struct{}
+
+func (prometheusRecorder) IncLevel(level string) {
+	// already done when the metrics were enabled, it only orders the read of errLogCounter
+	registerErrLogCounter()
+	errLogCounter.WithLabelValues(level).Add(1)
+}
+
 type metricHook struct{}
 
 func (m metricHook) Levels() []logrus.Level {
@@ -24,6 +91,9 @@ func (m metricHook) Levels() []logrus.Level {
 }
 
 func (m metricHook) Fire(entry *logrus.Entry) error {
-	errLogCounter.WithLabelValues(entry.Level.String()).Add(1)
+	if isLogrusNotice(entry) {
+		return nil
+	}
+	getMetricRecorder().IncLevel(entry.Level.String())
 	return nil
 }
@@ -0,0 +1,76 @@
+package log
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+type countingRecorder struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (r *countingRecorder) IncLevel(level string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counts[level]++
+}
+
+func TestSetMetricRecorder(t *testing.T) {
+	r := &countingRecorder{counts: map[string]int{}}
+	SetMetricRecorder(r)
+	defer SetMetricRecorder(nil)
+
+	w := newCustomWriter(io.Discard)
+	w.enableMetrics()
+	for _, line := range []string{
+		`{"level":"info","message":"ok"}`,
+		`{"level":"warn","message":"slow"}`,
+		`{"level":"error","message":"failed"}`,
+		`{"level":"error","message":"failed again"}`,
+	} {
+		_, err := w.Write([]byte(line))
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, map[string]int{"WARN": 1, "ERROR": 2}, r.counts)
+}
+
+func TestSetProdEnvRegistersMetric(t *testing.T) {
+	prevRegisterer, prevCounter := prometheus.DefaultRegisterer, errLogCounter
+	prevMetrics, prevLevel := metricsEnabled.Load(), logLevel.Load()
+	defer func() {
+		prometheus.DefaultRegisterer = prevRegisterer
+		errLogCounter = prevCounter
+		errLogCounterOnce = sync.Once{}
+		metricsEnabled.Store(prevMetrics)
+		logLevel.Store(prevLevel)
+	}()
+	registry := prometheus.NewRegistry()
+	prometheus.DefaultRegisterer = registry
+	errLogCounterOnce = sync.Once{}
+	metricsEnabled.Store(false)
+
+	isRegistered := func() bool {
+		probe := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "go_service_log_error_count"}, []string{"level"})
+		if err := registry.Register(probe); err != nil {
+			var are prometheus.AlreadyRegisteredError
+			return errors.As(err, &are)
+		}
+		registry.Unregister(probe)
+		return false
+	}
+
+	useLogger(t, LoggerTypeZerolog)
+	SetOutput(io.Discard)
+	assert.False(t, isRegistered())
+
+	// registered before anything is logged
+	SetProdEnv()
+	assert.True(t, isRegistered())
+}
@@ -0,0 +1,79 @@
+package log
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/kitex/pkg/endpoint"
+	"github.com/cloudwego/kitex/pkg/rpcinfo"
+)
+
+// KitexMiddleware returns a kitex server middleware writing one access log line per call with the
+// method, the status ("ok", "error" or the biz status code), the numeric duration_ms and the error
+// if any. The trace id comes from the request context. Failed calls are logged at error level
+// and biz errors at warn level, e.g.
+//
+//	server.WithMiddleware(log.KitexMiddleware())
+func KitexMiddleware() endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, req, resp interface{}) error {
+			start := time.Now()
+			err := next(ctx, req, resp)
+
+			method := "-"
+			status := "ok"
+			level := LevelInfo
+			if ri := rpcinfo.GetRPCInfo(ctx); ri != nil && ri.Invocation() != nil {
+				method = ri.Invocation().ServiceName() + "/" + ri.Invocation().MethodName()
+				if bizErr := ri.Invocation().BizStatusErr(); bizErr != nil {
+					status = strconv.Itoa(int(bizErr.BizStatusCode()))
+					level = LevelWarn
+				}
+			}
+			kv := []any{"method", method, "status", status}
+			if err != nil {
+				kv = []any{"method", method, "status", "error", "error", err}
+				level = LevelError
+			}
+			logAccess(ctx, level, "kitex access", time.Since(start), kv)
+			return err
+		}
+	}
+}
+
+// HertzMiddleware returns a hertz middleware writing one access log line per request with the
+// method, path, status code and numeric duration_ms. The trace id comes from the request context.
+// 5xx responses are logged at error level and 4xx ones at warn level, e.g.
+//
+//	h.Use(log.HertzMiddleware())
+func HertzMiddleware() app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		start := time.Now()
+		c.Next(ctx)
+
+		status := c.Response.StatusCode()
+		level := LevelInfo
+		switch {
+		case status >= 500:
+			level = LevelError
+		case status >= 400:
+			level = LevelWarn
+		}
+		kv := []any{"method", string(c.Method()), "path", string(c.Path()), "status", status}
+		logAccess(ctx, level, "hertz access", time.Since(start), kv)
+	}
+}
+
+func logAccess(ctx context.Context, level Level, msg string, d time.Duration, kv []any) {
+	ctx = context.WithValue(withKV(ctx, kv), durationCtxKey{}, d.Milliseconds())
+	switch level {
+	case LevelError:
+		getDefaultLogger().CtxErrorf(ctx, "%s", msg)
+	case LevelWarn:
+		getDefaultLogger().CtxWarnf(ctx, "%s", msg)
+	default:
+		getDefaultLogger().CtxInfof(ctx, "%s", msg)
+	}
+}
@@ -0,0 +1,55 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/kitex/pkg/rpcinfo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKitexMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	defer SetOutput(os.Stdout)
+
+	ri := rpcinfo.NewRPCInfo(nil, nil, rpcinfo.NewInvocation("UserService", "GetUser"), nil, nil)
+	ctx := rpcinfo.NewCtxWithRPCInfo(context.Background(), ri)
+
+	mw := KitexMiddleware()
+	ok := mw(func(ctx context.Context, req, resp interface{}) error { return nil })
+	assert.NoError(t, ok(ctx, nil, nil))
+	assert.Contains(t, buf.String(), `"method":"UserService/GetUser"`)
+	assert.Contains(t, buf.String(), `"status":"ok"`)
+	assert.Contains(t, buf.String(), `"duration_ms":`)
+	assert.Contains(t, buf.String(), ": kitex access\n")
+
+	buf.Reset()
+	failed := mw(func(ctx context.Context, req, resp interface{}) error { return errors.New("db down") })
+	assert.Error(t, failed(ctx, nil, nil))
+	assert.Contains(t, buf.String(), `"error":"db down"`)
+	assert.Contains(t, buf.String(), `"status":"error"`)
+}
+
+func TestHertzMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	defer SetOutput(os.Stdout)
+
+	c := app.NewContext(0)
+	c.Request.SetMethod("POST")
+	c.Request.SetRequestURI("/users")
+	c.SetHandlers([]app.HandlerFunc{HertzMiddleware(), func(ctx context.Context, c *app.RequestContext) {
+		c.SetStatusCode(503)
+	}})
+	c.Next(context.Background())
+
+	assert.Contains(t, buf.String(), `"method":"POST"`)
+	assert.Contains(t, buf.String(), `"path":"/users"`)
+	assert.Contains(t, buf.String(), `"status":"503"`)
+	assert.Contains(t, buf.String(), ": hertz access\n")
+}
@@ -0,0 +1,41 @@
+package log
+
+import "context"
+
+// OperationKey is the custom field the operation set by SetOperation is logged under
+const OperationKey = "op"
+
+type operationCtxKey struct{}
+
+// SetOperation returns a context whose log lines carry name, e.g. the endpoint "GET /users/:id",
+// as the op custom field. A custom field op of the context takes precedence over it
+func SetOperation(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, operationCtxKey{}, name)
+}
+
+// OperationFromContext returns the operation set by SetOperation
+func OperationFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(operationCtxKey{}).(string)
+	return name, ok
+}
+
+// withOperationField adds the operation of ctx to fields, returning fields itself when there's none
+func withOperationField(ctx context.Context, fields map[string]string) map[string]string {
+	if ctx == nil {
+		return fields
+	}
+	name, ok := OperationFromContext(ctx)
+	if !ok {
+		return fields
+	}
+	if _, exists := fields[OperationKey]; exists {
+		return fields
+	}
+
+	merged := make(map[string]string, len(fields)+1)
+	for k, v := range fields {
+		merged[k] = v
+	}
+	merged[OperationKey] = name
+	return merged
+}
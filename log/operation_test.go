@@ -0,0 +1,30 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetOperation(t *testing.T) {
+	var buf bytes.Buffer
+	zlog := zerolog.New(newCustomWriter(&buf)).Hook(customFieldsHook{})
+
+	ctx := SetOperation(context.WithValue(context.Background(), TraceIDKey, "t-1"), "GET /users")
+	ctx = AppendLogKv(ctx, "user", "42")
+	zlog.Info().Ctx(ctx).Str(TraceIDKey, "t-1").Msg("served")
+	assert.Contains(t, buf.String(), ` t-1 `)
+	assert.Contains(t, buf.String(), `{"op":"GET /users","user":"42"} : served`)
+
+	name, ok := OperationFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "GET /users", name)
+
+	// a custom field op of the context wins
+	buf.Reset()
+	zlog.Info().Ctx(AppendLogKv(ctx, OperationKey, "custom")).Msg("overridden")
+	assert.Contains(t, buf.String(), `{"op":"custom","user":"42"} : overridden`)
+}
@@ -0,0 +1,115 @@
+package log
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// rateLimiter counts the lines of each format string within the current interval
+type rateLimiter struct {
+	limit    int
+	interval time.Duration
+	stop     chan struct{}
+
+	mu     sync.Mutex
+	counts map[string]*rateCount
+}
+
+type rateCount struct {
+	seen       int
+	suppressed int
+}
+
+var currentRateLimiter atomic.Pointer[rateLimiter]
+
+// SetRateLimit lets at most n lines of each format string through every interval, so a broken loop
+// can't flood the log pipeline. The repeats are dropped and, at the end of the interval, a notice line
+// reports how many were suppressed per format. Suppressed warn and error lines still count in the
+// error metrics once SetProdEnv enabled them, fatal lines are never suppressed.
+// It applies to the package functions such as Error and CtxError, n <= 0 disables it, the default.
+func SetRateLimit(n int, interval time.Duration) {
+	var rl *rateLimiter
+	if n > 0 && interval > 0 {
+		rl = &rateLimiter{limit: n, interval: interval, stop: make(chan struct{}), counts: make(map[string]*rateCount)}
+	}
+	if prev := currentRateLimiter.Swap(rl); prev != nil {
+		close(prev.stop)
+		prev.flush()
+	}
+	if rl != nil {
+		go rl.run()
+	}
+}
+
+// allowLog reports whether a line of format at level may be emitted. Lines filtered out by the level
+// don't count against the limit, the logger drops them anyway.
+func allowLog(level Level, format string) bool {
+	rl := currentRateLimiter.Load()
+	if rl == nil || level >= LevelFatal || !levelEnabled(level) {
+		return true
+	}
+	if rl.allow(format) {
+		return true
+	}
+	if metricsEnabled.Load() && level >= LevelWarn {
+		countSuppressed(level)
+	}
+	return false
+}
+
+func (rl *rateLimiter) allow(format string) bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	c, ok := rl.counts[format]
+	if !ok {
+		c = &rateCount{}
+		rl.counts[format] = c
+	}
+	c.seen++
+	if c.seen <= rl.limit {
+		return true
+	}
+	c.suppressed++
+	return false
+}
+
+func (rl *rateLimiter) run() {
+	ticker := time.NewTicker(rl.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-rl.stop:
+			return
+		case <-ticker.C:
+			rl.flush()
+		}
+	}
+}
+
+// flush starts a new interval and logs the suppressed counts of the one that ended
+func (rl *rateLimiter) flush() {
+	rl.mu.Lock()
+	counts := rl.counts
+	rl.counts = make(map[string]*rateCount, len(counts))
+	rl.mu.Unlock()
+
+	for format, c := range counts {
+		if c.suppressed > 0 {
+			getDefaultLogger().Noticef("log rate limit: suppressed %d occurrences of %q in the last %s", c.suppressed, format, rl.interval)
+		}
+	}
+}
+
+// countSuppressed counts a suppressed line in the error metrics, with the level name the writer would use
+func countSuppressed(level Level) {
+	name := "WARN"
+	if level >= LevelError {
+		name = "ERROR"
+	}
+	if GetLoggerType() == LoggerTypeLogrus {
+		name = strings.ToLower(name)
+	}
+	getMetricRecorder().IncLevel(name)
+}
@@ -0,0 +1,87 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/kitex/pkg/klog"
+	"github.com/stretchr/testify/assert"
+)
+
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestSetRateLimit(t *testing.T) {
+	prevMetrics := metricsEnabled.Load()
+	defer func() {
+		SetRateLimit(0, 0)
+		SetMetricRecorder(nil)
+		metricsEnabled.Store(prevMetrics)
+	}()
+
+	useLogger(t, LoggerTypeZerolog).enableMetrics()
+	metricsEnabled.Store(true)
+	recorder := &countingRecorder{counts: map[string]int{}}
+	SetMetricRecorder(recorder)
+	var out lockedBuffer
+	SetOutput(&out)
+
+	SetRateLimit(2, 50*time.Millisecond)
+	for i := 0; i < 10; i++ {
+		Error("query failed: attempt %d", i)
+	}
+	Info("other format")
+	assert.Equal(t, 3, strings.Count(out.String(), "\n"))
+	assert.Contains(t, out.String(), "attempt 1")
+	assert.NotContains(t, out.String(), "attempt 2")
+
+	assert.Eventually(t, func() bool {
+		return strings.Contains(out.String(), `suppressed 8 occurrences of "query failed: attempt %d"`)
+	}, time.Second, 5*time.Millisecond)
+	recorder.mu.Lock()
+	assert.Equal(t, 10, recorder.counts["ERROR"])
+	recorder.mu.Unlock()
+
+	// a new interval lets the format through again
+	Error("query failed: attempt %d", 10)
+	assert.Contains(t, out.String(), "attempt 10")
+}
+
+func TestSetRateLimitFilteredLevel(t *testing.T) {
+	defer SetRateLimit(0, 0)
+
+	l := useLogger(t, LoggerTypeZerolog)
+	l.SetLevel(klog.LevelInfo)
+	var out lockedBuffer
+	SetOutput(&out)
+
+	SetRateLimit(1, time.Hour)
+	for i := 0; i < 3; i++ {
+		Debug("cache miss %d", i)
+	}
+	assert.Empty(t, out.String())
+
+	// the filtered lines didn't use the budget of the format
+	l.SetLevel(klog.LevelDebug)
+	Debug("cache miss %d", 3)
+	Debug("cache miss %d", 4)
+	assert.Contains(t, out.String(), "cache miss 3")
+	assert.NotContains(t, out.String(), "cache miss 4")
+}
@@ -0,0 +1,40 @@
+package log
+
+import (
+	"context"
+	"runtime/debug"
+)
+
+// PanicStackKey is the custom field RecoverAndLog logs the stack of the panic under
+const PanicStackKey = "panic_stack"
+
+// RecoverAndLog recovers a panic and logs it at error level with its stack and the trace id of ctx,
+// the panic is swallowed. It must be deferred directly, e.g.
+//
+//	go func() {
+//		defer log.RecoverAndLog(ctx)
+//		...
+//	}()
+//
+// The line counts in the error metrics once SetProdEnv enabled them.
+func RecoverAndLog(ctx context.Context) {
+	if r := recover(); r != nil {
+		logPanic(ctx, r)
+	}
+}
+
+// RecoverLogAndPanic is RecoverAndLog re-raising the panic once it's logged, it must be deferred directly.
+func RecoverLogAndPanic(ctx context.Context) {
+	if r := recover(); r != nil {
+		logPanic(ctx, r)
+		panic(r)
+	}
+}
+
+func logPanic(ctx context.Context, r any) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx = AppendLogKv(ctx, PanicStackKey, string(debug.Stack()))
+	getDefaultLogger().CtxErrorf(ctx, "recovered panic: %v", r)
+}
@@ -0,0 +1,36 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecoverAndLog(t *testing.T) {
+	for _, typ := range []LoggerType{LoggerTypeZerolog, LoggerTypeLogrus} {
+		useLogger(t, typ)
+		var buf bytes.Buffer
+		SetOutput(&buf)
+
+		ctx := AppendLogKv(context.Background(), "job", "sync")
+		assert.NotPanics(t, func() {
+			defer RecoverAndLog(ctx)
+			panic("boom")
+		})
+		out := buf.String()
+		assert.Contains(t, out, "ERROR")
+		assert.Contains(t, out, ": recovered panic: boom\n")
+		assert.Contains(t, out, `"job":"sync"`)
+		assert.Contains(t, out, `"panic_stack":"goroutine`)
+		assert.Contains(t, out, "recover_test.go")
+
+		buf.Reset()
+		assert.PanicsWithValue(t, "again", func() {
+			defer RecoverLogAndPanic(ctx)
+			panic("again")
+		})
+		assert.Contains(t, buf.String(), ": recovered panic: again\n")
+	}
+}
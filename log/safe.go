@@ -0,0 +1,51 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// Redactable is implemented by the values that mask themselves when logged with Safe,
+// e.g. a credit card number printing only its last 4 digits.
+type Redactable interface {
+	Redacted() string
+}
+
+// Safe logs at info level like CtxInfo, the args implementing Redactable are replaced by their
+// Redacted form before formatting, the other args format normally.
+func Safe(ctx context.Context, format string, v ...interface{}) {
+	getDefaultLogger().CtxInfof(ctx, format, redactArgs(v)...)
+}
+
+// SafeSprintf formats like fmt.Sprintf with the args implementing Redactable redacted, e.g. to log
+// at another level: log.CtxWarn(ctx, "%s", log.SafeSprintf("charge %v failed", card))
+func SafeSprintf(format string, v ...interface{}) string {
+	return fmt.Sprintf(format, redactArgs(v)...)
+}
+
+// redactArgs returns v with the Redactable args replaced by their Redacted form, v itself when there are none
+func redactArgs(v []interface{}) []interface{} {
+	var redacted []interface{}
+	for i, arg := range v {
+		r, ok := arg.(Redactable)
+		if !ok || isNilPointer(arg) {
+			continue
+		}
+		if redacted == nil {
+			redacted = make([]interface{}, len(v))
+			copy(redacted, v)
+		}
+		redacted[i] = r.Redacted()
+	}
+	if redacted == nil {
+		return v
+	}
+	return redacted
+}
+
+// isNilPointer reports whether v is a typed nil pointer, whose Redacted method may not be callable
+func isNilPointer(v interface{}) bool {
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
@@ -0,0 +1,40 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type creditCard string
+
+func (c creditCard) Redacted() string {
+	return "****" + string(c[len(c)-4:])
+}
+
+type secret string
+
+func (s *secret) Redacted() string {
+	return "[redacted]"
+}
+
+func TestSafeSprintf(t *testing.T) {
+	var nilSecret *secret
+	pwd := secret("pwd")
+	assert.Equal(t, "card ****4242 of bob, [redacted] <nil>",
+		SafeSprintf("card %v of %s, %s %v", creditCard("4242424242424242"), "bob", &pwd, nilSecret))
+	assert.Equal(t, "plain 42", SafeSprintf("plain %d", 42))
+}
+
+func TestSafe(t *testing.T) {
+	useLogger(t, LoggerTypeZerolog)
+	var buf bytes.Buffer
+	SetOutput(&buf)
+
+	Safe(context.Background(), "charged %v", creditCard("4242424242424242"))
+	assert.Regexp(t, `safe_test\.go:\d+ `, buf.String())
+	assert.Contains(t, buf.String(), ": charged ****4242\n")
+	assert.NotContains(t, buf.String(), "42424242")
+}
@@ -0,0 +1,42 @@
+package log
+
+import (
+	"sync"
+
+	"github.com/cloudwego/kitex/pkg/klog"
+)
+
+var switchMu sync.Mutex
+
+// SwitchLogger rebuilds the logger with the backend t at runtime, keeping the level, the output
+// and the metrics of the current one. Unlike SetLoggerType it can be called once logging started:
+// the new logger is fully configured before it replaces the current one, goroutines logging meanwhile
+// use either. A logger set with SetLogger stays the default one. Kitex and hertz keep the logger they
+// were given, call WithKitex and WithHertz again after the switch.
+func SwitchLogger(t LoggerType) {
+	switchMu.Lock()
+	defer switchMu.Unlock()
+
+	prev := GetLogger()
+	if prev.loggerType == t {
+		return
+	}
+	out := prev.output()
+
+	SetLoggerType(t)
+	l := newLogger()
+	if out != nil {
+		l.SetOutput(out)
+	}
+	l.SetLevel(prev.getLevel())
+	if metricsEnabled.Load() {
+		l.enableMetrics()
+	}
+
+	// a logger set with SetLogger meanwhile wins
+	if cur := defaultLogger.Load(); *cur == klog.FullLogger(prev) {
+		var next klog.FullLogger = l
+		defaultLogger.CompareAndSwap(cur, &next)
+	}
+	logger.Store(l)
+}
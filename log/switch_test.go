@@ -0,0 +1,74 @@
+package log
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/cloudwego/kitex/pkg/klog"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSwitchLogger(t *testing.T) {
+	prevLevel := GetLogLevel()
+	defer func() {
+		SetLevel(prevLevel)
+	}()
+
+	useLogger(t, LoggerTypeZerolog)
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	SetLevel(LevelInfo)
+
+	SwitchLogger(LoggerTypeLogrus)
+	assert.Equal(t, LoggerTypeLogrus, GetLoggerType())
+	assert.Equal(t, klog.FullLogger(GetLogger()), getDefaultLogger())
+
+	Debug("dropped")
+	Info("from %s", "logrus")
+	assert.NotContains(t, buf.String(), "dropped")
+	assert.Contains(t, buf.String(), "   INFO ")
+	assert.Regexp(t, `switch_test\.go:\d+ `, buf.String())
+	assert.Contains(t, buf.String(), ": from logrus\n")
+
+	buf.Reset()
+	SwitchLogger(LoggerTypeZerolog)
+	Debug("dropped")
+	Info("from %s", "zerolog")
+	assert.NotContains(t, buf.String(), "dropped")
+	assert.Contains(t, buf.String(), " INFO ")
+	assert.Contains(t, buf.String(), ": from zerolog\n")
+}
+
+func TestSwitchLoggerConcurrent(t *testing.T) {
+	useLogger(t, LoggerTypeZerolog)
+	var out lockedBuffer
+	SetOutput(&out)
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					Info("concurrent")
+				}
+			}
+		}()
+	}
+	for i := 0; i < 20; i++ {
+		SwitchLogger([]LoggerType{LoggerTypeLogrus, LoggerTypeZerolog}[i%2])
+	}
+	close(stop)
+	wg.Wait()
+
+	Info("concurrent")
+	assert.Equal(t, LoggerTypeZerolog, GetLogger().loggerType)
+	assert.Contains(t, out.String(), ": concurrent\n")
+}
@@ -0,0 +1,94 @@
+package log
+
+import (
+	"context"
+	"time"
+
+	"github.com/cloudwego/kitex/pkg/klog"
+	kitexlogrus "github.com/kitex-contrib/obs-opentelemetry/logging/logrus"
+)
+
+// DurationKey is the custom field Timed logs the elapsed milliseconds under, as a number
+const DurationKey = "duration_ms"
+
+// DeadlineKey is the custom field the milliseconds left before the deadline of the context are logged
+// under, as a number, negative once the deadline passed. It's skipped when the context has no deadline
+const DeadlineKey = "ctx_deadline_ms"
+
+type durationCtxKey struct{}
+
+// Timed starts a timer and returns a func that logs "<name> done" at info level with the elapsed
+// milliseconds as the numeric custom field duration_ms, e.g.
+//
+//	defer log.Timed(ctx, "get_user")()
+func Timed(ctx context.Context, name string) func() {
+	start := time.Now()
+	return func() {
+		logDuration(ctx, name, time.Since(start))
+	}
+}
+
+// logDuration keeps the call depth of the CtxInfo path, so the caller is the one of the done func
+func logDuration(ctx context.Context, name string, d time.Duration) {
+	ctx = context.WithValue(ctx, durationCtxKey{}, d.Milliseconds())
+	dl := getDefaultLogger()
+	l, ok := dl.(*Logger)
+	if !ok {
+		dl.CtxInfof(ctx, "%s done", name)
+		return
+	}
+	switch fl := l.FullLogger.(type) {
+	case *kitexlogrus.Logger:
+		fl.Logger().WithContext(ctx).Infof("%s done", name)
+	case *zerologLogger:
+		fl.logf(ctx, klog.LevelInfo, "%s done", name)
+	default:
+		fl.CtxInfof(ctx, "%s done", name)
+	}
+}
+
+// durationFromContext returns the duration recorded by Timed
+func durationFromContext(ctx context.Context) (int64, bool) {
+	ms, ok := ctx.Value(durationCtxKey{}).(int64)
+	return ms, ok
+}
+
+// customFieldsValue returns the value the custom fields are emitted with: nil when there are none,
+// fields plus the numeric fields of ctx (duration_ms, ctx_deadline_ms) when it has some, fields otherwise
+func customFieldsValue(ctx context.Context, fields map[string]string) any {
+	numeric := numericFields(ctx)
+	if len(numeric) == 0 {
+		if fields == nil {
+			return nil
+		}
+		return fields
+	}
+
+	merged := make(map[string]any, len(fields)+len(numeric))
+	for k, v := range fields {
+		merged[k] = v
+	}
+	for k, v := range numeric {
+		merged[k] = v
+	}
+	return merged
+}
+
+// numericFields returns the custom fields of ctx logged as numbers: the duration recorded by Timed
+// and the milliseconds left before the deadline of ctx. It's nil when there are none
+func numericFields(ctx context.Context) map[string]int64 {
+	if ctx == nil {
+		return nil
+	}
+	var numeric map[string]int64
+	if ms, ok := durationFromContext(ctx); ok {
+		numeric = map[string]int64{DurationKey: ms}
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		if numeric == nil {
+			numeric = make(map[string]int64, 1)
+		}
+		numeric[DeadlineKey] = time.Until(deadline).Milliseconds()
+	}
+	return numeric
+}
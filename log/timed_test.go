@@ -0,0 +1,46 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimed(t *testing.T) {
+	for _, typ := range []LoggerType{LoggerTypeZerolog, LoggerTypeLogrus} {
+		useLogger(t, typ)
+		var buf bytes.Buffer
+		SetOutput(&buf)
+
+		ctx := AppendLogKv(context.Background(), "user_id", "42")
+		done := Timed(ctx, "get_user")
+		done()
+
+		out := buf.String()
+		assert.Regexp(t, regexp.MustCompile(`"duration_ms":\d+`), out)
+		assert.Contains(t, out, `"user_id":"42"`)
+		assert.Regexp(t, `timed_test\.go:\d+ `, out)
+		assert.Contains(t, out, ": get_user done\n")
+	}
+}
+
+func TestDeadlineField(t *testing.T) {
+	for _, typ := range []LoggerType{LoggerTypeZerolog, LoggerTypeLogrus} {
+		useLogger(t, typ)
+		var buf bytes.Buffer
+		SetOutput(&buf)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		CtxInfo(ctx, "near expiry")
+		cancel()
+		assert.Regexp(t, regexp.MustCompile(`"ctx_deadline_ms":(59\d{3}|60000)[,}]`), buf.String())
+
+		buf.Reset()
+		CtxInfo(context.Background(), "no deadline")
+		assert.NotContains(t, buf.String(), DeadlineKey)
+	}
+}
@@ -0,0 +1,46 @@
+package log
+
+import (
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// truncatedMarker is appended to the messages and custom fields cut by the size limits
+const truncatedMarker = "...[truncated]"
+
+var (
+	maxMessageBytes atomic.Int64
+	maxCustomBytes  atomic.Int64
+)
+
+// SetMaxMessageBytes limits the message of a log line to n bytes, longer messages are cut and
+// end with "...[truncated]". n <= 0 means unlimited, the default.
+func SetMaxMessageBytes(n int) {
+	maxMessageBytes.Store(int64(n))
+}
+
+// SetMaxCustomBytes limits the serialized custom fields of a log line to n bytes like
+// SetMaxMessageBytes, a truncated blob is no longer valid JSON. n <= 0 means unlimited, the default.
+func SetMaxCustomBytes(n int) {
+	maxCustomBytes.Store(int64(n))
+}
+
+func truncateMessage(msg string) string {
+	return truncate(msg, maxMessageBytes.Load())
+}
+
+func truncateCustom(custom string) string {
+	return truncate(custom, maxCustomBytes.Load())
+}
+
+// truncate cuts s to at most n bytes without splitting a utf-8 sequence and appends truncatedMarker
+func truncate(s string, n int64) string {
+	if n <= 0 || int64(len(s)) <= n {
+		return s
+	}
+	cut := int(n)
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + truncatedMarker
+}
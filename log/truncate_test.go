@@ -0,0 +1,39 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		n    int64
+		want string
+	}{
+		{name: "unlimited", s: "hello", n: 0, want: "hello"},
+		{name: "fits", s: "hello", n: 5, want: "hello"},
+		{name: "cut", s: "hello world", n: 5, want: "hello" + truncatedMarker},
+		{name: "utf8 boundary", s: "日本語", n: 4, want: "日" + truncatedMarker},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, truncate(tt.s, tt.n))
+		})
+	}
+}
+
+func TestSetMaxMessageBytes(t *testing.T) {
+	SetMaxMessageBytes(8)
+	defer SetMaxMessageBytes(0)
+
+	var buf bytes.Buffer
+	w := newCustomWriter(&buf)
+	_, err := w.Write([]byte(`{"level":"info","message":"` + strings.Repeat("x", 100) + `"}`))
+	assert.NoError(t, err)
+	assert.True(t, strings.HasSuffix(buf.String(), " : xxxxxxxx"+truncatedMarker+"\n"), buf.String())
+}
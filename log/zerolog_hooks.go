@@ -8,13 +8,16 @@ import (
 type customFieldsHook struct{}
 
 func (h customFieldsHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	var customData map[string]string
 	ctx := e.GetCtx()
-	if ctx == nil {
-		return
+	if ctx != nil {
+		// Extract custom fields from context
+		customData = GetAllCustomFields(ctx)
 	}
 
-	// Extract custom fields from context
-	if customData, ok := ctx.Value(CustomFieldsKey).(map[string]string); ok {
-		e.Interface(CustomFieldsKey, customData)
+	if custom := customFieldsValue(ctx, withGlobalFields(withOperationField(ctx, customData))); custom != nil {
+		if bytes, err := marshalCustomFields(custom); err == nil {
+			e.RawJSON(getFieldNames().custom, bytes)
+		}
 	}
 }
@@ -0,0 +1,141 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync/atomic"
+
+	"github.com/cloudwego/kitex/pkg/klog"
+	"github.com/rs/zerolog"
+)
+
+// zerologLogger is the klog.FullLogger of the zerolog backend. kitex's zerolog wrapper drops SetLevel and
+// SetOutput, and changing the level of a zerolog.Logger in place races with the lines being logged, so the
+// zerolog logger logs every level and the level is an atomic checked before the event is built.
+// Every method has the call depth of kitex's Infof/Logf, callerHook relies on it.
+type zerologLogger struct {
+	zl    *zerolog.Logger
+	out   *customWriter
+	level atomic.Int32 // klog.Level
+}
+
+var _ klog.FullLogger = (*zerologLogger)(nil)
+
+func (z *zerologLogger) enabled(level klog.Level) bool {
+	return level >= klog.Level(z.level.Load())
+}
+
+// event returns the event of a line at level, nil when the level is filtered out
+func (z *zerologLogger) event(ctx context.Context, level klog.Level) *zerolog.Event {
+	if !z.enabled(level) {
+		return nil
+	}
+	var e *zerolog.Event
+	switch level {
+	case klog.LevelNotice:
+		// zerolog has no notice level, emit a level-less event carrying the level field itself
+		e = z.zl.Log().Str(zerolog.LevelFieldName, levelNoticeValue)
+	default:
+		// WithLevel doesn't exit on fatal, unlike zerolog's Fatal, exitFatal runs the OnFatal handlers first
+		e = z.zl.WithLevel(toZerologLevel(level))
+	}
+	if ctx != nil {
+		e = e.Ctx(ctx)
+	}
+	return e
+}
+
+// logf emits a line at level, a fatal one exits after the OnFatal handlers ran once it's emitted,
+// unlike kitex's zerolog wrapper which logged Fatalf at error level without exiting
+func (z *zerologLogger) logf(ctx context.Context, level klog.Level, format string, v ...any) {
+	e := z.event(ctx, level)
+	if e == nil {
+		return
+	}
+	e.Msg(fmt.Sprintf(format, v...))
+	if level == klog.LevelFatal {
+		exitFatal(1)
+	}
+}
+
+func (z *zerologLogger) log(level klog.Level, v ...any) {
+	e := z.event(nil, level)
+	if e == nil {
+		return
+	}
+	e.Msg(fmt.Sprint(v...))
+	if level == klog.LevelFatal {
+		exitFatal(1)
+	}
+}
+
+func (z *zerologLogger) Trace(v ...any)  { z.log(klog.LevelTrace, v...) }
+func (z *zerologLogger) Debug(v ...any)  { z.log(klog.LevelDebug, v...) }
+func (z *zerologLogger) Info(v ...any)   { z.log(klog.LevelInfo, v...) }
+func (z *zerologLogger) Notice(v ...any) { z.log(klog.LevelNotice, v...) }
+func (z *zerologLogger) Warn(v ...any)   { z.log(klog.LevelWarn, v...) }
+func (z *zerologLogger) Error(v ...any)  { z.log(klog.LevelError, v...) }
+func (z *zerologLogger) Fatal(v ...any)  { z.log(klog.LevelFatal, v...) }
+
+func (z *zerologLogger) Tracef(format string, v ...any)  { z.logf(nil, klog.LevelTrace, format, v...) }
+func (z *zerologLogger) Debugf(format string, v ...any)  { z.logf(nil, klog.LevelDebug, format, v...) }
+func (z *zerologLogger) Infof(format string, v ...any)   { z.logf(nil, klog.LevelInfo, format, v...) }
+func (z *zerologLogger) Noticef(format string, v ...any) { z.logf(nil, klog.LevelNotice, format, v...) }
+func (z *zerologLogger) Warnf(format string, v ...any)   { z.logf(nil, klog.LevelWarn, format, v...) }
+func (z *zerologLogger) Errorf(format string, v ...any)  { z.logf(nil, klog.LevelError, format, v...) }
+func (z *zerologLogger) Fatalf(format string, v ...any)  { z.logf(nil, klog.LevelFatal, format, v...) }
+
+func (z *zerologLogger) CtxTracef(ctx context.Context, format string, v ...any) {
+	z.logf(ctx, klog.LevelTrace, format, v...)
+}
+
+func (z *zerologLogger) CtxDebugf(ctx context.Context, format string, v ...any) {
+	z.logf(ctx, klog.LevelDebug, format, v...)
+}
+
+func (z *zerologLogger) CtxInfof(ctx context.Context, format string, v ...any) {
+	z.logf(ctx, klog.LevelInfo, format, v...)
+}
+
+func (z *zerologLogger) CtxNoticef(ctx context.Context, format string, v ...any) {
+	z.logf(ctx, klog.LevelNotice, format, v...)
+}
+
+func (z *zerologLogger) CtxWarnf(ctx context.Context, format string, v ...any) {
+	z.logf(ctx, klog.LevelWarn, format, v...)
+}
+
+func (z *zerologLogger) CtxErrorf(ctx context.Context, format string, v ...any) {
+	z.logf(ctx, klog.LevelError, format, v...)
+}
+
+func (z *zerologLogger) CtxFatalf(ctx context.Context, format string, v ...any) {
+	z.logf(ctx, klog.LevelFatal, format, v...)
+}
+
+func (z *zerologLogger) SetLevel(level klog.Level) {
+	z.level.Store(int32(level))
+}
+
+// SetOutput replaces the output behind the custom format writer
+func (z *zerologLogger) SetOutput(w io.Writer) {
+	z.out.setOutput(w)
+}
+
+// toZerologLevel returns the zerolog level lines at level are emitted with, trace lines are emitted
+// at debug level like kitex's zerolog wrapper does
+func toZerologLevel(level klog.Level) zerolog.Level {
+	switch level {
+	case klog.LevelTrace, klog.LevelDebug:
+		return zerolog.DebugLevel
+	case klog.LevelInfo:
+		return zerolog.InfoLevel
+	case klog.LevelError:
+		return zerolog.ErrorLevel
+	case klog.LevelFatal:
+		return zerolog.FatalLevel
+	default:
+		return zerolog.WarnLevel
+	}
+}
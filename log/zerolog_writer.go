@@ -1,54 +1,118 @@
 package log
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"strings"
+	"sync/atomic"
 	"time"
 
-	"github.com/bytedance/sonic"
 	"github.com/rs/zerolog"
 )
 
+// levelNoticeValue is the level field value of notice lines, zerolog has no notice level
+const levelNoticeValue = "notice"
+
 // customWriter wraps an io.Writer and formats zerolog JSON output
 // into the custom format: time level pid gid trace_id caller custom : msg
 type customWriter struct {
-	out          io.Writer
-	enableMetric bool
+	out          atomic.Pointer[writerHolder]
+	enableMetric atomic.Bool
 }
 
 func newCustomWriter(w io.Writer) *customWriter {
-	return &customWriter{
-		out:          w,
-		enableMetric: false,
-	}
+	cw := &customWriter{}
+	cw.setOutput(w)
+	return cw
+}
+
+func (w *customWriter) setOutput(out io.Writer) {
+	w.out.Store(&writerHolder{out})
+}
+
+func (w *customWriter) output() io.Writer {
+	return w.out.Load().Writer
 }
 
 func (w *customWriter) enableMetrics() {
-	w.enableMetric = true
+	w.enableMetric.Store(true)
 }
 
-func (w *customWriter) Write(p []byte) (n int, err error) {
-	// Parse zerolog JSON output using sonic (faster than encoding/json)
+// logLine holds the fields customWriter prints. Decoding into it with the struct field bindings
+// avoids building a map for every line, fields it doesn't know about are skipped.
+type logLine struct {
+	Time    string          `json:"time"`
+	Level   string          `json:"level"`
+	Message string          `json:"message"`
+	TraceID string          `json:"trace_id"`
+	Caller  string          `json:"caller"`
+	Custom  json.RawMessage `json:"ctx_extra_data"`
+}
+
+// decodeLogLine decodes a zerolog JSON line, Time is returned formatted and Level as the lower case name.
+// Lines the struct can't bind (renamed zerolog fields, numeric time or level) fall back to a generic map.
+func decodeLogLine(p []byte) (*logLine, error) {
+	if hasDefaultFieldNames() {
+		line := &logLine{}
+		if err := getJSONCodec().Unmarshal(p, line); err == nil {
+			line.Time = formatTimeString(line.Time)
+			return line, nil
+		}
+	}
+	return decodeLogLineMap(p)
+}
+
+// decodeLogLineMap is the slow path of decodeLogLine
+func decodeLogLineMap(p []byte) (*logLine, error) {
 	var logEntry map[string]interface{}
-	if err = sonic.Unmarshal(p, &logEntry); err != nil {
+	if err := getJSONCodec().Unmarshal(p, &logEntry); err != nil {
+		return nil, err
+	}
+
+	line := &logLine{
+		Time:    formatTime(logEntry[zerolog.TimestampFieldName]),
+		Level:   strings.ToLower(formatLevel(logEntry[zerolog.LevelFieldName])),
+		Message: getString(logEntry[zerolog.MessageFieldName]),
+		TraceID: getString(logEntry[TraceIDKey]),
+		Caller:  getString(logEntry[zerolog.CallerFieldName]),
+	}
+	if customData, ok := logEntry[getFieldNames().custom]; ok {
+		if bytes, err := marshalCustomFields(customData); err == nil {
+			line.Custom = bytes
+		}
+	}
+	return line, nil
+}
+
+// hasDefaultFieldNames reports whether zerolog and SetFieldNames still use the field names logLine is bound to
+func hasDefaultFieldNames() bool {
+	return getFieldNames().custom == CustomFieldsKey &&
+		zerolog.TimestampFieldName == "time" &&
+		zerolog.LevelFieldName == "level" &&
+		zerolog.MessageFieldName == "message" &&
+		zerolog.CallerFieldName == "caller"
+}
+
+func (w *customWriter) Write(p []byte) (n int, err error) {
+	line, err := decodeLogLine(p)
+	if err != nil {
 		// If parsing fails, write original content
-		return w.out.Write(p)
+		return w.output().Write(p)
 	}
 
-	// Extract fields from JSON
-	logTime := formatTime(logEntry[zerolog.TimestampFieldName])
-	level := formatLevel(logEntry[zerolog.LevelFieldName])
-	msg := getString(logEntry[zerolog.MessageFieldName])
+	level := "INFO"
+	if line.Level != "" {
+		level = strings.ToUpper(line.Level)
+	}
 
-	// Try multiple possible trace ID field names
-	traceId := getString(logEntry[TraceIDKey])
+	traceId := line.TraceID
 	if traceId == "" {
 		traceId = placeholder
 	}
 
-	// Use caller from zerolog (configured with CallerWithSkipFrameCount)
-	caller := getString(logEntry[zerolog.CallerFieldName])
+	// Use caller from zerolog, set by callerHook
+	caller := line.Caller
 	if caller == "" {
 		caller = placeholder
 	}
@@ -56,32 +120,48 @@ func (w *customWriter) Write(p []byte) (n int, err error) {
 	pid := GetPID()
 	gid := GetGID()
 
-	// Extract custom fields
 	custom := "{}"
-	if customData, ok := logEntry[CustomFieldsKey]; ok {
-		if bytes, err := sonic.Marshal(customData); err == nil {
-			custom = string(bytes)
-		}
+	if len(line.Custom) > 0 && string(line.Custom) != "null" {
+		custom = string(line.Custom)
 	}
 
 	// Update metrics if enabled
-	if w.enableMetric {
-		levelStr := getString(logEntry[zerolog.LevelFieldName])
-		w.updateMetrics(levelStr)
+	if w.enableMetric.Load() {
+		w.updateMetrics(line.Level)
 	}
 
 	// Format output
-	output := fmt.Sprintf("%v %v %v %v %v %v %v : %v\n",
-		logTime, level, pid, gid, traceId, caller, custom, msg)
+	var output string
+	if getOutputFormat() == FormatLogfmt {
+		output = formatLogfmt(line.Time, level, pid, gid, traceId, caller, line.Custom, line.Message)
+	} else {
+		output = fmt.Sprintf("%v %v %v %v %v %v %v : %v\n",
+			line.Time, level, pid, gid, traceId, caller, truncateCustom(custom), truncateMessage(line.Message))
+	}
+
+	if hasHooks() {
+		fields := make(map[string]string)
+		if len(line.Custom) > 0 {
+			var customData map[string]interface{}
+			if err := getJSONCodec().Unmarshal(line.Custom, &customData); err == nil {
+				for k, v := range customData {
+					fields[k] = getString(v)
+				}
+			}
+		}
+		if traceId != placeholder {
+			fields[getFieldNames().traceID] = traceId
+		}
+		fireHooks(parseLevel(line.Level), line.Message, fields)
+	}
 
-	return w.out.Write([]byte(output))
+	return outputFor(w.output(), line.Level).Write([]byte(output))
 }
 
 func (w *customWriter) updateMetrics(levelStr string) {
 	// Count error, warn, fatal, panic logs
-	switch levelStr {
-	case "warn", "error", "fatal", "panic":
-		errLogCounter.WithLabelValues(strings.ToUpper(levelStr)).Add(1)
+	if isErrorLevel(levelStr) {
+		getMetricRecorder().IncLevel(strings.ToUpper(levelStr))
 	}
 }
 
@@ -93,11 +173,7 @@ func formatTime(t interface{}) string {
 
 	switch v := t.(type) {
 	case string:
-		// Parse ISO8601 format from zerolog
-		if parsed, err := time.Parse(time.RFC3339, v); err == nil {
-			return parsed.Format(defaultTimestampFormat)
-		}
-		return v
+		return formatTimeString(v)
 	case float64:
 		// Unix timestamp
 		return time.Unix(int64(v), 0).Format(defaultTimestampFormat)
@@ -106,6 +182,17 @@ func formatTime(t interface{}) string {
 	}
 }
 
+// formatTimeString formats the ISO8601 timestamp from zerolog
+func formatTimeString(t string) string {
+	if t == "" {
+		return time.Now().Format(defaultTimestampFormat)
+	}
+	if parsed, err := time.Parse(time.RFC3339, t); err == nil {
+		return parsed.Format(defaultTimestampFormat)
+	}
+	return t
+}
+
 // formatLevel formats the log level with padding
 func formatLevel(l interface{}) string {
 	if l == nil {
@@ -115,9 +202,11 @@ func formatLevel(l interface{}) string {
 	var levelStr string
 	switch v := l.(type) {
 	case string:
+		// includes "notice", which is written as a string by Logger.Noticef
 		levelStr = strings.ToUpper(v)
 	case float64:
 		// zerolog uses numeric levels: trace=-1, debug=0, info=1, warn=2, error=3, fatal=4, panic=5
+		// notice has no zerolog level and is always written as a string
 		levels := []string{"TRACE", "DEBUG", "INFO", "WARN", "ERROR", "FATAL", "PANIC"}
 		idx := int(v) + 1
 		if idx >= 0 && idx < len(levels) {
@@ -0,0 +1,54 @@
+package log
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var benchLine = []byte(`{"level":"info","trace_id":"4bf92f3577b34da6a3ce929d0e0e4736","span_id":"00f067aa0ba902b7",` +
+	`"ctx_extra_data":{"app_id":"2","user_id":"1234"},"time":"2024-03-01T12:00:00+08:00",` +
+	`"caller":"/app/handler/user.go:42","message":"get user 1234 done"}` + "\n")
+
+func TestDecodeLogLine(t *testing.T) {
+	fast, err := decodeLogLine(benchLine)
+	assert.NoError(t, err)
+	slow, err := decodeLogLineMap(benchLine)
+	assert.NoError(t, err)
+
+	assert.Equal(t, slow.Time, fast.Time)
+	assert.Equal(t, "info", fast.Level)
+	assert.Equal(t, slow.Level, fast.Level)
+	assert.Equal(t, slow.Message, fast.Message)
+	assert.Equal(t, slow.TraceID, fast.TraceID)
+	assert.Equal(t, slow.Caller, fast.Caller)
+	assert.JSONEq(t, string(slow.Custom), string(fast.Custom))
+
+	numeric, err := decodeLogLine([]byte(`{"level":1,"time":1709265600,"message":"m"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "info", numeric.Level)
+	assert.Equal(t, "m", numeric.Message)
+}
+
+func BenchmarkCustomWriter(b *testing.B) {
+	b.Run("struct", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = decodeLogLine(benchLine)
+		}
+	})
+	b.Run("map", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = decodeLogLineMap(benchLine)
+		}
+	})
+	b.Run("write", func(b *testing.B) {
+		w := newCustomWriter(io.Discard)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = w.Write(benchLine)
+		}
+	})
+}
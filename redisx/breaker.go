@@ -0,0 +1,88 @@
+package redisx
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without calling redis while the breaker set by WithBreaker is open
+var ErrCircuitOpen = errors.New("redisx: circuit breaker is open")
+
+// Breaker decides whether calls may reach redis. Allow is called before every call and
+// Record with its result, errors included (redis.Nil, WRONGTYPE, ...), so the implementation
+// decides which errors count as failures.
+type Breaker interface {
+	Allow() bool
+	Record(err error)
+}
+
+// WithBreaker guards every ZQueue and HashMap call with b, calls fail fast with ErrCircuitOpen
+// while b doesn't allow them. With WithRetry each attempt goes through b.
+// The same Breaker may be shared by several structures using the same client.
+func WithBreaker(b Breaker) Option {
+	return func(o *options) {
+		o.breaker = b
+	}
+}
+
+// guard runs fn through the breaker set by WithBreaker, WRONGTYPE replies are returned as *WrongTypeError
+func (o options) guard(fn func() error) error {
+	if o.breaker == nil {
+		return wrapWrongType(fn(), o.key, o.keyType)
+	}
+	if !o.breaker.Allow() {
+		return ErrCircuitOpen
+	}
+	err := fn()
+	o.breaker.Record(err)
+	return wrapWrongType(err, o.key, o.keyType)
+}
+
+// NewBreaker returns the default Breaker. It opens after failures consecutive connection errors
+// (the errors WithRetry retries), rejects calls for cooldown, then lets a single trial call through:
+// the breaker closes when it succeeds and opens again when it fails.
+func NewBreaker(failures int, cooldown time.Duration) Breaker {
+	if failures <= 0 {
+		failures = 1
+	}
+	return &breaker{failures: failures, cooldown: cooldown, now: now}
+}
+
+type breaker struct {
+	failures int
+	cooldown time.Duration
+	now      func() time.Time
+
+	mu          sync.Mutex
+	consecutive int
+	openUntil   time.Time
+	trial       bool // a trial call is in flight after the cooldown
+}
+
+func (b *breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.consecutive < b.failures {
+		return true
+	}
+	if b.trial || b.now().Before(b.openUntil) {
+		return false
+	}
+	b.trial = true
+	return true
+}
+
+func (b *breaker) Record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+	if err == nil || !isRetryable(err) {
+		b.consecutive = 0
+		return
+	}
+	b.consecutive++
+	if b.consecutive >= b.failures {
+		b.openUntil = b.now().Add(b.cooldown)
+	}
+}
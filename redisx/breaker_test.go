@@ -0,0 +1,56 @@
+package redisx
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := NewBreaker(2, time.Second).(*breaker)
+	b.now = func() time.Time { return now }
+	o := newOptions(WithBreaker(b))
+
+	fail := func() error { return io.EOF }
+	ok := func() error { return nil }
+
+	// logical errors don't count as failures
+	assert.ErrorIs(t, o.guard(func() error { return redis.Nil }), redis.Nil)
+	assert.ErrorIs(t, o.guard(fail), io.EOF)
+	assert.ErrorIs(t, o.guard(fail), io.EOF)
+
+	calls := 0
+	err := o.guard(func() error {
+		calls++
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Zero(t, calls)
+
+	// after the cooldown a single trial is let through, a failure opens the breaker again
+	now = now.Add(time.Second)
+	assert.True(t, b.Allow())
+	assert.False(t, b.Allow())
+	b.Record(io.EOF)
+	assert.ErrorIs(t, o.guard(ok), ErrCircuitOpen)
+
+	// a successful trial closes it
+	now = now.Add(time.Second)
+	assert.NoError(t, o.guard(ok))
+	assert.NoError(t, o.guard(ok))
+}
+
+func TestBreakerWithRetry(t *testing.T) {
+	o := newOptions(WithBreaker(NewBreaker(2, time.Minute)), WithRetry(5, 0))
+	calls := 0
+	err := o.retry(t.Context(), func() error {
+		calls++
+		return io.EOF
+	})
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, 2, calls)
+}
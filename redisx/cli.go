@@ -7,3 +7,6 @@ var GlobalClient redis.UniversalClient
 func SetClient(cli redis.UniversalClient) {
 	GlobalClient = cli
 }
+
+// scanCount is the COUNT hint of the SCAN family commands
+const scanCount = 100
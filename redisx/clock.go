@@ -0,0 +1,53 @@
+package redisx
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Clock tells the current time of the time-based helpers, tests inject a fixed one with SetClock or WithClock.
+// It only affects the times computed by the package, the times computed by redis (TTLs, TIME) follow the server clock.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a func to Clock, e.g. ClockFunc(time.Now)
+type ClockFunc func() time.Time
+
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+var clock atomic.Pointer[Clock]
+
+// SetClock replaces the package clock, used by the instances without WithClock. nil restores time.Now.
+func SetClock(c Clock) {
+	if c == nil {
+		clock.Store(nil)
+		return
+	}
+	clock.Store(&c)
+}
+
+// now returns the time of the package clock
+func now() time.Time {
+	if c := clock.Load(); c != nil {
+		return (*c).Now()
+	}
+	return time.Now()
+}
+
+// WithClock sets the clock of a ZQueue or HashMap, overriding the package clock set by SetClock
+func WithClock(c Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
+// now returns the time of the instance clock, the package clock when there's none
+func (o options) now() time.Time {
+	if o.clock != nil {
+		return o.clock.Now()
+	}
+	return now()
+}
@@ -0,0 +1,36 @@
+package redisx
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClock(t *testing.T) {
+	fixed := time.Unix(1700000000, 0)
+	SetClock(ClockFunc(func() time.Time { return fixed }))
+	defer SetClock(nil)
+
+	assert.Equal(t, fixed, newOptions().now())
+
+	other := fixed.Add(time.Hour)
+	assert.Equal(t, other, newOptions(WithClock(ClockFunc(func() time.Time { return other }))).now())
+
+	SetClock(nil)
+	assert.WithinDuration(t, time.Now(), newOptions().now(), time.Second)
+}
+
+func TestBreaker_PackageClock(t *testing.T) {
+	current := time.Unix(0, 0)
+	SetClock(ClockFunc(func() time.Time { return current }))
+	defer SetClock(nil)
+
+	b := NewBreaker(1, time.Second)
+	b.Record(io.EOF)
+	assert.False(t, b.Allow())
+
+	current = current.Add(time.Second)
+	assert.True(t, b.Allow())
+}
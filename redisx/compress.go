@@ -0,0 +1,49 @@
+package redisx
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+)
+
+// compressHeader prefixes the values compressed by WithCompression. It starts with a NUL byte,
+// which text values don't contain
+const compressHeader = "\x00rxgz"
+
+// encodeValue compresses s when compression is enabled and s reaches the threshold. A value that
+// already starts with compressHeader is always compressed, so it can't be mistaken for one on reads
+func (o options) encodeValue(s string) (string, error) {
+	if !o.compress || (len(s) < o.compressMin && !strings.HasPrefix(s, compressHeader)) {
+		return s, nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(compressHeader)
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(s)); err != nil {
+		return "", err
+	}
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// decodeValue reverses encodeValue, values without the header are returned as-is
+func (o options) decodeValue(s string) (string, error) {
+	if !o.compress || !strings.HasPrefix(s, compressHeader) {
+		return s, nil
+	}
+
+	zr, err := gzip.NewReader(strings.NewReader(s[len(compressHeader):]))
+	if err != nil {
+		return "", err
+	}
+	defer zr.Close()
+	b, err := io.ReadAll(zr)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,51 @@
+package redisx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressionRoundTrip(t *testing.T) {
+	o := newOptions(WithCompression(16))
+	large := strings.Repeat("payload ", 64)
+
+	tests := []struct {
+		name       string
+		value      string
+		compressed bool
+	}{
+		{name: "empty", value: ""},
+		{name: "below threshold", value: "small"},
+		{name: "binary below threshold", value: "\x1f\x8b\x00"},
+		{name: "at threshold", value: strings.Repeat("x", 16), compressed: true},
+		{name: "large", value: large, compressed: true},
+		{name: "small value with header", value: compressHeader + "x", compressed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enc, err := o.encodeValue(tt.value)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.compressed, strings.HasPrefix(enc, compressHeader))
+			dec, err := o.decodeValue(enc)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.value, dec)
+		})
+	}
+
+	enc, _ := o.encodeValue(large)
+	assert.Less(t, len(enc), len(large))
+}
+
+func TestCompressionDisabled(t *testing.T) {
+	o := newOptions()
+	enc, err := o.encodeValue(strings.Repeat("x", 1024))
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Repeat("x", 1024), enc)
+
+	// the header is only interpreted when the option is set
+	dec, err := o.decodeValue(compressHeader + "raw")
+	assert.NoError(t, err)
+	assert.Equal(t, compressHeader+"raw", dec)
+}
@@ -24,8 +24,8 @@ func IncrByClient(ctx context.Context, cli redis.UniversalClient, key string, ex
     return current
 `)
 
-	result, err := script.Run(ctx, cli, []string{key}, int(expire.Seconds())).Result()
-	return result.(int64), err
+	result, err := script.Run(ctx, cli, []string{key}, int(expire.Seconds())).Int64()
+	return result, wrapWrongType(err, key, "string")
 }
 
 func DecrByClient(ctx context.Context, cli redis.UniversalClient, key string, expire time.Duration) (int64, error) {
@@ -37,6 +37,6 @@ func DecrByClient(ctx context.Context, cli redis.UniversalClient, key string, ex
 	return current
 `)
 
-	result, err := script.Run(ctx, cli, []string{key}, int(expire.Seconds())).Result()
-	return result.(int64), err
+	result, err := script.Run(ctx, cli, []string{key}, int(expire.Seconds())).Int64()
+	return result, wrapWrongType(err, key, "string")
 }
@@ -0,0 +1,49 @@
+package redisx
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Deduper remembers ids for a while, e.g. "have I processed this webhook event in the last hour"
+// Every id is stored as its own key Prefix:id expiring after the given ttl
+type Deduper struct {
+	Prefix string
+	Cli    redis.UniversalClient
+}
+
+func NewDeduper(cli redis.UniversalClient, prefix string) *Deduper {
+	return &Deduper{
+		Prefix: prefix,
+		Cli:    cli,
+	}
+}
+
+// ErrInvalidTTL is returned by Deduper.Seen for a ttl <= 0, which would record the id forever
+var ErrInvalidTTL = errors.New("redisx: Deduper needs a positive ttl")
+
+// Seen atomically checks and records id, it returns true if id was already recorded within ttl
+// Uses SET NX PX, so concurrent callers for the same id get exactly one false
+// ttl must be positive, ErrInvalidTTL is returned otherwise
+func (d *Deduper) Seen(ctx context.Context, id string, ttl time.Duration) (bool, error) {
+	if ttl <= 0 {
+		return false, ErrInvalidTTL
+	}
+	ok, err := d.Cli.SetNX(ctx, d.key(id), 1, ttl).Result()
+	if err != nil {
+		return false, err
+	}
+	return !ok, nil
+}
+
+// Forget removes id so it's treated as unseen again, e.g. when processing it failed
+func (d *Deduper) Forget(ctx context.Context, id string) error {
+	return d.Cli.Del(ctx, d.key(id)).Err()
+}
+
+func (d *Deduper) key(id string) string {
+	return d.Prefix + ":" + id
+}
@@ -0,0 +1,86 @@
+package redisx
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeduper_Seen(t *testing.T) {
+	s, cli := newTestClient(t)
+	ctx := context.Background()
+	d := NewDeduper(cli, "webhook")
+
+	seen, err := d.Seen(ctx, "evt-1", time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, seen)
+	assert.True(t, s.Exists("webhook:evt-1"))
+	assert.Equal(t, time.Minute, s.TTL("webhook:evt-1"))
+
+	seen, err = d.Seen(ctx, "evt-1", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, seen)
+
+	seen, err = d.Seen(ctx, "evt-2", time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, seen)
+
+	s.FastForward(time.Minute)
+	seen, err = d.Seen(ctx, "evt-1", time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, seen)
+}
+
+func TestDeduper_Forget(t *testing.T) {
+	_, cli := newTestClient(t)
+	ctx := context.Background()
+	d := NewDeduper(cli, "webhook")
+
+	_, err := d.Seen(ctx, "evt-1", time.Minute)
+	assert.NoError(t, err)
+	assert.NoError(t, d.Forget(ctx, "evt-1"))
+	assert.NoError(t, d.Forget(ctx, "missing"))
+
+	seen, err := d.Seen(ctx, "evt-1", time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, seen)
+}
+
+func TestDeduper_InvalidTTL(t *testing.T) {
+	s, cli := newTestClient(t)
+	ctx := context.Background()
+	d := NewDeduper(cli, "webhook")
+
+	for _, ttl := range []time.Duration{0, -time.Second} {
+		seen, err := d.Seen(ctx, "evt-1", ttl)
+		assert.ErrorIs(t, err, ErrInvalidTTL)
+		assert.False(t, seen)
+	}
+	assert.False(t, s.Exists("webhook:evt-1"))
+}
+
+func TestDeduper_Concurrent(t *testing.T) {
+	_, cli := newTestClient(t)
+	ctx := context.Background()
+	d := NewDeduper(cli, "webhook")
+
+	var unseen atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			seen, err := d.Seen(ctx, "evt-1", time.Minute)
+			assert.NoError(t, err)
+			if !seen {
+				unseen.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), unseen.Load())
+}
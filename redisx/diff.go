@@ -0,0 +1,85 @@
+package redisx
+
+import (
+	"context"
+
+	"github.com/mbeoliero/kit/utils/typex"
+	"github.com/redis/go-redis/v9"
+)
+
+// Diff returns the members of the sorted set keyA missing from keyB and the members of keyB missing
+// from keyA, decoded with typex.ToAnyE, e.g. to reconcile a primary set and its cache.
+// It uses ZDIFF (redis 6.2), and falls back to comparing both sets client side on older servers
+// and, in cluster mode, when the keys live in different slots
+func Diff[T any](ctx context.Context, cli redis.UniversalClient, keyA, keyB string) (onlyA, onlyB []T, err error) {
+	a, b, err := diffServerSide(ctx, cli, keyA, keyB)
+	if isUnknownCommand(err) || isCrossSlot(err) {
+		a, b, err = diffClientSide(ctx, cli, keyA, keyB)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if onlyA, err = decodeMembers[T](a); err != nil {
+		return nil, nil, err
+	}
+	if onlyB, err = decodeMembers[T](b); err != nil {
+		return nil, nil, err
+	}
+	return onlyA, onlyB, nil
+}
+
+// diffServerSide runs both ZDIFFs in one pipeline
+func diffServerSide(ctx context.Context, cli redis.UniversalClient, keyA, keyB string) ([]string, []string, error) {
+	var aCmd, bCmd *redis.StringSliceCmd
+	_, err := cli.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		aCmd = pipe.ZDiff(ctx, keyA, keyB)
+		bCmd = pipe.ZDiff(ctx, keyB, keyA)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return aCmd.Val(), bCmd.Val(), nil
+}
+
+// diffClientSide reads both sets whole and compares them
+func diffClientSide(ctx context.Context, cli redis.UniversalClient, keyA, keyB string) ([]string, []string, error) {
+	a, err := cli.ZRange(ctx, keyA, 0, -1).Result()
+	if err != nil {
+		return nil, nil, err
+	}
+	b, err := cli.ZRange(ctx, keyB, 0, -1).Result()
+	if err != nil {
+		return nil, nil, err
+	}
+	return missingFrom(a, b), missingFrom(b, a), nil
+}
+
+// missingFrom returns the members of a not in b, in the order of a
+func missingFrom(a, b []string) []string {
+	inB := make(map[string]struct{}, len(b))
+	for _, m := range b {
+		inB[m] = struct{}{}
+	}
+	var missing []string
+	for _, m := range a {
+		if _, ok := inB[m]; !ok {
+			missing = append(missing, m)
+		}
+	}
+	return missing
+}
+
+// decodeMembers decodes raw members into T, a member that doesn't parse yields a *DecodeError
+func decodeMembers[T any](raw []string) ([]T, error) {
+	members := make([]T, 0, len(raw))
+	for _, s := range raw {
+		m, err := typex.ToAnyE[T](s)
+		if err != nil {
+			return nil, &DecodeError{Member: s, Err: err}
+		}
+		members = append(members, m)
+	}
+	return members, nil
+}
@@ -0,0 +1,33 @@
+package redisx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	_, cli := newTestClient(t)
+	ctx := context.Background()
+	assert.NoError(t, cli.ZAdd(ctx, "primary", redis.Z{Member: "1", Score: 1}, redis.Z{Member: "2", Score: 2}, redis.Z{Member: "3", Score: 3}).Err())
+	assert.NoError(t, cli.ZAdd(ctx, "cache", redis.Z{Member: "2", Score: 20}, redis.Z{Member: "4", Score: 4}).Err())
+
+	// miniredis has no ZDIFF, this runs the client side comparison
+	onlyA, onlyB, err := Diff[int](ctx, cli, "primary", "cache")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 3}, onlyA)
+	assert.Equal(t, []int{4}, onlyB)
+
+	onlyA, onlyB, err = Diff[int](ctx, cli, "primary", "missing")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, onlyA)
+	assert.Empty(t, onlyB)
+
+	assert.NoError(t, cli.ZAdd(ctx, "cache", redis.Z{Member: "x", Score: 5}).Err())
+	_, _, err = Diff[int](ctx, cli, "primary", "cache")
+	assert.ErrorIs(t, err, ErrDecodeMember)
+
+	assert.Equal(t, []string{"a", "c"}, missingFrom([]string{"a", "b", "c"}, []string{"b", "d"}))
+}
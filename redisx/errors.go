@@ -0,0 +1,83 @@
+package redisx
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrMemberNotFound is returned by the ZQueue methods that need a member to be in the set
+var ErrMemberNotFound = errors.New("redisx: member not found")
+
+// ErrWrongType matches (errors.Is) the *WrongTypeError returned when a key holds another type than
+// the one of the call, e.g. a HashMap opened on a string key
+var ErrWrongType = errors.New("redisx: wrong type")
+
+// ErrFieldTTLUnsupported is returned by HashMap.SetMultiWithFieldTTL when the server doesn't
+// support hash field expiry, added in redis 7.4
+var ErrFieldTTLUnsupported = errors.New("redisx: hash field ttl requires redis 7.4 or later")
+
+// ErrDecodeMember matches (errors.Is) the *DecodeError returned when a member can't be decoded into
+// the element type of a ZQueue
+var ErrDecodeMember = errors.New("redisx: can't decode member")
+
+// DecodeError holds the member a ZQueue failed to decode, either because the client handed out
+// something else than a string or because the string doesn't parse as the element type
+type DecodeError struct {
+	Member any
+	Err    error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("redisx: can't decode member %v: %v", e.Member, e.Err)
+}
+
+func (e *DecodeError) Is(target error) bool {
+	return target == ErrDecodeMember
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// WrongTypeError wraps the WRONGTYPE reply of redis with the key and the type the call expected
+type WrongTypeError struct {
+	Key      string
+	Expected string // the redis type name: "zset", "hash" or "string"
+	Err      error
+}
+
+func (e *WrongTypeError) Error() string {
+	return fmt.Sprintf("redisx: key %s doesn't hold a %s: %v", e.Key, e.Expected, e.Err)
+}
+
+func (e *WrongTypeError) Is(target error) bool {
+	return target == ErrWrongType
+}
+
+func (e *WrongTypeError) Unwrap() error {
+	return e.Err
+}
+
+// wrapWrongType wraps err in *WrongTypeError when it's a WRONGTYPE reply, also when raised by a script
+func wrapWrongType(err error, key, expected string) error {
+	var redisErr redis.Error
+	if err == nil || !errors.As(err, &redisErr) || !strings.Contains(redisErr.Error(), "WRONGTYPE") {
+		return err
+	}
+	return &WrongTypeError{Key: key, Expected: expected, Err: err}
+}
+
+// isUnknownCommand reports whether err is the reply of redis to a command it doesn't support
+func isUnknownCommand(err error) bool {
+	var redisErr redis.Error
+	return errors.As(err, &redisErr) && strings.Contains(strings.ToLower(redisErr.Error()), "unknown command")
+}
+
+// isCrossSlot reports whether err is the reply of redis cluster to a command on keys of different slots
+func isCrossSlot(err error) bool {
+	var redisErr redis.Error
+	return errors.As(err, &redisErr) && strings.HasPrefix(redisErr.Error(), "CROSSSLOT")
+}
@@ -0,0 +1,39 @@
+package redisx
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrWrongType(t *testing.T) {
+	mr, cli := newTestClient(t)
+	ctx := context.Background()
+	assert.NoError(t, mr.Set("users", "plain"))
+
+	_, err := NewHashMap[string, string](cli, "users").Get(ctx, "bob")
+	assert.ErrorIs(t, err, ErrWrongType)
+	var wrongType *WrongTypeError
+	assert.True(t, errors.As(err, &wrongType))
+	assert.Equal(t, "users", wrongType.Key)
+	assert.Equal(t, "hash", wrongType.Expected)
+	assert.Contains(t, err.Error(), "WRONGTYPE")
+
+	err = NewHashMap[string, string](cli, "users").SetKeepTTL(ctx, "bob", "x", time.Minute)
+	assert.ErrorIs(t, err, ErrWrongType)
+
+	_, err = NewZQueue[string](cli, "users", false).Count(ctx)
+	assert.ErrorIs(t, err, ErrWrongType)
+
+	mr.HSet("profile", "name", "bob")
+	_, err = GetByClient[string](ctx, cli, "profile")
+	assert.ErrorIs(t, err, ErrWrongType)
+	_, err = IncrByClient(ctx, cli, "profile", time.Minute)
+	assert.ErrorIs(t, err, ErrWrongType)
+
+	_, err = NewHashMap[string, string](cli, "profile").Get(ctx, "name")
+	assert.NoError(t, err)
+}
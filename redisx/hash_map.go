@@ -3,6 +3,9 @@ package redisx
 import (
 	"context"
 	"errors"
+	"fmt"
+	"reflect"
+	"strings"
 	"time"
 
 	"github.com/mbeoliero/kit/utils/typex"
@@ -12,61 +15,229 @@ import (
 type HashMap[K comparable, V any] struct {
 	Key string
 	Cli redis.UniversalClient
+
+	opts options
 }
 
-func NewHashMap[K comparable, V any](cli redis.UniversalClient, key string) *HashMap[K, V] {
+func NewHashMap[K comparable, V any](cli redis.UniversalClient, key string, opts ...Option) *HashMap[K, V] {
+	o := newOptions(opts...)
+	o.key, o.keyType = o.buildKey(key), "hash"
 	return &HashMap[K, V]{
-		Key: key,
-		Cli: cli,
+		Key:  o.key,
+		Cli:  cli,
+		opts: o,
 	}
 }
 
 // Set sets a field in the hash
 func (h *HashMap[K, V]) Set(ctx context.Context, field K, value V, expire time.Duration) error {
-	pipe := h.Cli.Pipeline()
-	pipe.HSet(ctx, h.Key, typex.ToString(field), typex.ToString(value))
-	if expire > 0 {
-		pipe.Expire(ctx, h.Key, expire)
+	ctx = h.withOperation(ctx, "Set")
+	val, err := h.Encode(value)
+	if err != nil {
+		return err
+	}
+	return h.opts.retry(ctx, func() error {
+		pipe := h.Cli.Pipeline()
+		pipe.HSet(ctx, h.Key, typex.ToString(field), val)
+		if expire > 0 {
+			pipe.Expire(ctx, h.Key, expire)
+		}
+		_, err := pipe.Exec(ctx)
+		return err
+	})
+}
+
+// SetTx queues the Set of field into tx, the expire (> 0) is queued right after it.
+// An encoding error is returned by the Exec of tx
+func (h *HashMap[K, V]) SetTx(tx *Tx, field K, value V, expire time.Duration) {
+	val, err := h.Encode(value)
+	if err != nil {
+		tx.fail(err)
+		return
+	}
+	f := typex.ToString(field)
+	tx.queue(h.Cli, func(ctx context.Context, pipe redis.Pipeliner) {
+		pipe.HSet(ctx, h.Key, f, val)
+		if expire > 0 {
+			pipe.Expire(ctx, h.Key, expire)
+		}
+	})
+}
+
+// DeleteTx queues the deletion of fields into tx
+func (h *HashMap[K, V]) DeleteTx(tx *Tx, fields ...K) {
+	if len(fields) == 0 {
+		return
+	}
+	fieldStrs := make([]string, 0, len(fields))
+	for _, field := range fields {
+		fieldStrs = append(fieldStrs, typex.ToString(field))
+	}
+	tx.queue(h.Cli, func(ctx context.Context, pipe redis.Pipeliner) {
+		pipe.HDel(ctx, h.Key, fieldStrs...)
+	})
+}
+
+// setKeepTTLScript sets the field ARGV[1] to ARGV[2] and applies the expire ARGV[3] (ms)
+// only when the key has no expiry yet
+var setKeepTTLScript = redis.NewScript(`
+redis.call("HSET", KEYS[1], ARGV[1], ARGV[2])
+if tonumber(ARGV[3]) > 0 and redis.call("PTTL", KEYS[1]) == -1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[3])
+end
+return 1
+`)
+
+// SetKeepTTL sets a field without refreshing the expiry of the hash, unlike Set: expire only applies
+// when the hash has none, i.e. to the write creating it. A hash written steadily still expires
+// expire after its creation
+func (h *HashMap[K, V]) SetKeepTTL(ctx context.Context, field K, value V, expire time.Duration) error {
+	ctx = h.withOperation(ctx, "SetKeepTTL")
+	val, err := h.Encode(value)
+	if err != nil {
+		return err
 	}
-	_, err := pipe.Exec(ctx)
-	return err
+	return h.opts.retry(ctx, func() error {
+		return setKeepTTLScript.Run(ctx, h.Cli, []string{h.Key}, typex.ToString(field), val, expire.Milliseconds()).Err()
+	})
+}
+
+// setIfChangedScript sets the field ARGV[1] to ARGV[2] and applies the expire ARGV[3] (ms)
+// unless the field already holds that value, returns 1 when it wrote
+var setIfChangedScript = redis.NewScript(`
+if redis.call("HGET", KEYS[1], ARGV[1]) == ARGV[2] then
+	return 0
+end
+redis.call("HSET", KEYS[1], ARGV[1], ARGV[2])
+if tonumber(ARGV[3]) > 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[3])
+end
+return 1
+`)
+
+// SetIfChanged sets a field unless it already holds value, compared in its encoded form, and reports
+// whether it wrote. The expire is only refreshed on writes
+func (h *HashMap[K, V]) SetIfChanged(ctx context.Context, field K, value V, expire time.Duration) (bool, error) {
+	ctx = h.withOperation(ctx, "SetIfChanged")
+	val, err := h.Encode(value)
+	if err != nil {
+		return false, err
+	}
+	var written int64
+	err = h.opts.retry(ctx, func() (err error) {
+		written, err = setIfChangedScript.Run(ctx, h.Cli, []string{h.Key}, typex.ToString(field), val, expire.Milliseconds()).Int64()
+		return err
+	})
+	return written == 1, err
 }
 
 // SetMulti sets multiple fields in the hash
+// With WithBatchSize the fields are written in chunks, one pipeline flush per chunk,
+// and a failure is reported as *BatchError
 func (h *HashMap[K, V]) SetMulti(ctx context.Context, fields map[K]V, expire time.Duration) error {
+	ctx = h.withOperation(ctx, "SetMulti")
 	if len(fields) == 0 {
 		return nil
 	}
 
-	values := make(map[string]interface{}, len(fields))
+	// field/value pairs, flattened as HSET expects them
+	values := make([]interface{}, 0, 2*len(fields))
 	for k, v := range fields {
-		values[typex.ToString(k)] = typex.ToString(v)
+		val, err := h.Encode(v)
+		if err != nil {
+			return err
+		}
+		values = append(values, typex.ToString(k), val)
 	}
 
-	pipe := h.Cli.Pipeline()
-	pipe.HSet(ctx, h.Key, values)
-	if expire > 0 {
-		pipe.Expire(ctx, h.Key, expire)
+	chunks := typex.Chunk(values, 2*h.opts.batchSize)
+	for i, chunk := range chunks {
+		err := h.opts.retry(ctx, func() error {
+			pipe := h.Cli.Pipeline()
+			pipe.HSet(ctx, h.Key, chunk...)
+			if expire > 0 {
+				pipe.Expire(ctx, h.Key, expire)
+			}
+			_, err := pipe.Exec(ctx)
+			return err
+		})
+		if err != nil {
+			if len(chunks) == 1 {
+				return err
+			}
+			return &BatchError{Succeeded: i, Total: len(chunks), Err: err}
+		}
 	}
-	_, err := pipe.Exec(ctx)
-	return err
+	return nil
+}
+
+// FieldTTL is a value of SetMultiWithFieldTTL with the expiry of its own field
+type FieldTTL[V any] struct {
+	Value V
+	TTL   time.Duration // <= 0 keeps the field without expiry
+}
+
+// SetMultiWithFieldTTL sets multiple fields, each expiring after its own TTL (HEXPIRE, second precision),
+// while the hash itself keeps its expiry. The writes run in a MULTI transaction, so on servers older
+// than redis 7.4 nothing is written and ErrFieldTTLUnsupported is returned
+func (h *HashMap[K, V]) SetMultiWithFieldTTL(ctx context.Context, fields map[K]FieldTTL[V]) error {
+	ctx = h.withOperation(ctx, "SetMultiWithFieldTTL")
+	if len(fields) == 0 {
+		return nil
+	}
+
+	values := make([]interface{}, 0, 2*len(fields))
+	ttls := make(map[string]time.Duration, len(fields))
+	for k, f := range fields {
+		val, err := h.Encode(f.Value)
+		if err != nil {
+			return err
+		}
+		field := typex.ToString(k)
+		values = append(values, field, val)
+		if f.TTL > 0 {
+			ttls[field] = f.TTL
+		}
+	}
+
+	return h.opts.retry(ctx, func() error {
+		cmds, err := h.Cli.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, h.Key, values...)
+			for field, ttl := range ttls {
+				pipe.HExpire(ctx, h.Key, ttl, field)
+			}
+			return nil
+		})
+		for _, cmd := range cmds {
+			if isUnknownCommand(cmd.Err()) {
+				return fmt.Errorf("%w: %v", ErrFieldTTLUnsupported, cmd.Err())
+			}
+		}
+		return err
+	})
 }
 
 // Get gets a field from the hash
 func (h *HashMap[K, V]) Get(ctx context.Context, field K) (V, error) {
+	ctx = h.withOperation(ctx, "Get")
 	var res V
-	val, err := h.Cli.HGet(ctx, h.Key, typex.ToString(field)).Result()
+	var val string
+	err := h.opts.retry(ctx, func() (err error) {
+		val, err = h.Cli.HGet(ctx, h.Key, typex.ToString(field)).Result()
+		return err
+	})
 	if err != nil {
 		if errors.Is(err, redis.Nil) {
 			return res, nil
 		}
 		return res, err
 	}
-	return typex.ToAnyE[V](val)
+	return h.Decode(val)
 }
 
 // GetMulti gets multiple fields from the hash
 func (h *HashMap[K, V]) GetMulti(ctx context.Context, fields []K) (map[K]V, error) {
+	ctx = h.withOperation(ctx, "GetMulti")
 	if len(fields) == 0 {
 		return make(map[K]V), nil
 	}
@@ -76,7 +247,11 @@ func (h *HashMap[K, V]) GetMulti(ctx context.Context, fields []K) (map[K]V, erro
 		fieldStrs = append(fieldStrs, typex.ToString(field))
 	}
 
-	vals, err := h.Cli.HMGet(ctx, h.Key, fieldStrs...).Result()
+	var vals []any
+	err := h.opts.retry(ctx, func() (err error) {
+		vals, err = h.Cli.HMGet(ctx, h.Key, fieldStrs...).Result()
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -84,7 +259,7 @@ func (h *HashMap[K, V]) GetMulti(ctx context.Context, fields []K) (map[K]V, erro
 	result := make(map[K]V, len(fields))
 	for i, val := range vals {
 		if val != nil {
-			v, err := typex.ToAnyE[V](val.(string))
+			v, err := h.Decode(val.(string))
 			if err != nil {
 				return nil, err
 			}
@@ -97,7 +272,12 @@ func (h *HashMap[K, V]) GetMulti(ctx context.Context, fields []K) (map[K]V, erro
 
 // GetAll gets all fields and values from the hash
 func (h *HashMap[K, V]) GetAll(ctx context.Context) (map[K]V, error) {
-	vals, err := h.Cli.HGetAll(ctx, h.Key).Result()
+	ctx = h.withOperation(ctx, "GetAll")
+	var vals map[string]string
+	err := h.opts.retry(ctx, func() (err error) {
+		vals, err = h.Cli.HGetAll(ctx, h.Key).Result()
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -108,7 +288,7 @@ func (h *HashMap[K, V]) GetAll(ctx context.Context) (map[K]V, error) {
 		if err != nil {
 			return nil, err
 		}
-		val, err := typex.ToAnyE[V](v)
+		val, err := h.Decode(v)
 		if err != nil {
 			return nil, err
 		}
@@ -118,8 +298,81 @@ func (h *HashMap[K, V]) GetAll(ctx context.Context) (map[K]V, error) {
 	return result, nil
 }
 
+// GetAllInto reads all the fields of the hash into the struct dst points to. Fields are matched by the
+// mapstructure tag, as in the connector configs, or else by the struct field name, ignoring case.
+// Values are decoded as with typex.ToAnyE, a value that doesn't fit its struct field is reported with
+// the hash field name. Hash fields without struct field are ignored, the type V isn't used
+func (h *HashMap[K, V]) GetAllInto(ctx context.Context, dst any) error {
+	ctx = h.withOperation(ctx, "GetAllInto")
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("redisx: GetAllInto needs a non nil pointer to a struct, got %T", dst)
+	}
+
+	var vals map[string]string
+	err := h.opts.retry(ctx, func() (err error) {
+		vals, err = h.Cli.HGetAll(ctx, h.Key).Result()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	sv := rv.Elem()
+	st := sv.Type()
+	for i := 0; i < st.NumField(); i++ {
+		sf := st.Field(i)
+		name, ok := structFieldName(sf)
+		if !ok {
+			continue
+		}
+		field, raw, found := lookupField(vals, name)
+		if !found {
+			continue
+		}
+		s, err := h.opts.decodeValue(raw)
+		if err != nil {
+			return fmt.Errorf("redisx: hash field %s: %w", field, err)
+		}
+		if err := typex.Decode(s, sv.Field(i).Addr().Interface()); err != nil {
+			return fmt.Errorf("redisx: hash field %s into %s %s: %w", field, sf.Name, sf.Type, err)
+		}
+	}
+	return nil
+}
+
+// structFieldName returns the hash field name of sf: its mapstructure tag or its name.
+// It reports false for unexported fields and fields tagged "-"
+func structFieldName(sf reflect.StructField) (string, bool) {
+	if !sf.IsExported() {
+		return "", false
+	}
+	tag, _, _ := strings.Cut(sf.Tag.Get("mapstructure"), ",")
+	switch tag {
+	case "-":
+		return "", false
+	case "":
+		return sf.Name, true
+	}
+	return tag, true
+}
+
+// lookupField finds name in vals, exactly or else ignoring case, and returns the matching hash field
+func lookupField(vals map[string]string, name string) (string, string, bool) {
+	if v, ok := vals[name]; ok {
+		return name, v, true
+	}
+	for field, v := range vals {
+		if strings.EqualFold(field, name) {
+			return field, v, true
+		}
+	}
+	return "", "", false
+}
+
 // Delete deletes fields from the hash
 func (h *HashMap[K, V]) Delete(ctx context.Context, fields ...K) error {
+	ctx = h.withOperation(ctx, "Delete")
 	if len(fields) == 0 {
 		return nil
 	}
@@ -129,22 +382,107 @@ func (h *HashMap[K, V]) Delete(ctx context.Context, fields ...K) error {
 		fieldStrs = append(fieldStrs, typex.ToString(field))
 	}
 
-	return h.Cli.HDel(ctx, h.Key, fieldStrs...).Err()
+	return h.opts.retry(ctx, func() error {
+		return h.Cli.HDel(ctx, h.Key, fieldStrs...).Err()
+	})
+}
+
+// Apply sets the fields of sets and deletes the fields of deletes in a single MULTI transaction,
+// so readers see either none or all of the changes, then applies expire once. A field both set and
+// deleted ends up deleted
+func (h *HashMap[K, V]) Apply(ctx context.Context, sets map[K]V, deletes []K, expire time.Duration) error {
+	ctx = h.withOperation(ctx, "Apply")
+	if len(sets) == 0 && len(deletes) == 0 {
+		return nil
+	}
+
+	values := make([]interface{}, 0, 2*len(sets))
+	for k, v := range sets {
+		val, err := h.Encode(v)
+		if err != nil {
+			return err
+		}
+		values = append(values, typex.ToString(k), val)
+	}
+	fieldStrs := make([]string, 0, len(deletes))
+	for _, field := range deletes {
+		fieldStrs = append(fieldStrs, typex.ToString(field))
+	}
+
+	return h.opts.retry(ctx, func() error {
+		_, err := h.Cli.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			if len(values) > 0 {
+				pipe.HSet(ctx, h.Key, values...)
+			}
+			if len(fieldStrs) > 0 {
+				pipe.HDel(ctx, h.Key, fieldStrs...)
+			}
+			if expire > 0 {
+				pipe.Expire(ctx, h.Key, expire)
+			}
+			return nil
+		})
+		return err
+	})
 }
 
 // Exists checks if a field exists in the hash
 func (h *HashMap[K, V]) Exists(ctx context.Context, field K) (bool, error) {
-	return h.Cli.HExists(ctx, h.Key, typex.ToString(field)).Result()
+	ctx = h.withOperation(ctx, "Exists")
+	var exists bool
+	err := h.opts.retry(ctx, func() (err error) {
+		exists, err = h.Cli.HExists(ctx, h.Key, typex.ToString(field)).Result()
+		return err
+	})
+	return exists, err
+}
+
+// ExistsMulti checks several fields in one pipeline of HEXISTS and returns whether each one exists
+func (h *HashMap[K, V]) ExistsMulti(ctx context.Context, fields []K) (map[K]bool, error) {
+	ctx = h.withOperation(ctx, "ExistsMulti")
+	if len(fields) == 0 {
+		return make(map[K]bool), nil
+	}
+
+	cmds := make([]*redis.BoolCmd, len(fields))
+	err := h.opts.retry(ctx, func() error {
+		pipe := h.Cli.Pipeline()
+		for i, field := range fields {
+			cmds[i] = pipe.HExists(ctx, h.Key, typex.ToString(field))
+		}
+		_, err := pipe.Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[K]bool, len(fields))
+	for i, field := range fields {
+		result[field] = cmds[i].Val()
+	}
+	return result, nil
 }
 
 // Len returns the number of fields in the hash
 func (h *HashMap[K, V]) Len(ctx context.Context) (int64, error) {
-	return h.Cli.HLen(ctx, h.Key).Result()
+	ctx = h.withOperation(ctx, "Len")
+	var n int64
+	err := h.opts.retry(ctx, func() (err error) {
+		n, err = h.Cli.HLen(ctx, h.Key).Result()
+		return err
+	})
+	return n, err
 }
 
 // Keys returns all field names in the hash
 func (h *HashMap[K, V]) Keys(ctx context.Context) ([]K, error) {
-	keys, err := h.Cli.HKeys(ctx, h.Key).Result()
+	ctx = h.withOperation(ctx, "Keys")
+	var keys []string
+	err := h.opts.retry(ctx, func() (err error) {
+		keys, err = h.Cli.HKeys(ctx, h.Key).Result()
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -163,14 +501,19 @@ func (h *HashMap[K, V]) Keys(ctx context.Context) ([]K, error) {
 
 // Values returns all values in the hash
 func (h *HashMap[K, V]) Values(ctx context.Context) ([]V, error) {
-	vals, err := h.Cli.HVals(ctx, h.Key).Result()
+	ctx = h.withOperation(ctx, "Values")
+	var vals []string
+	err := h.opts.retry(ctx, func() (err error) {
+		vals, err = h.Cli.HVals(ctx, h.Key).Result()
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	result := make([]V, 0, len(vals))
 	for _, v := range vals {
-		val, err := typex.ToAnyE[V](v)
+		val, err := h.Decode(v)
 		if err != nil {
 			return nil, err
 		}
@@ -182,28 +525,142 @@ func (h *HashMap[K, V]) Values(ctx context.Context) ([]V, error) {
 
 // Incr increments the integer value of a field by the given amount
 func (h *HashMap[K, V]) Incr(ctx context.Context, field K, increment int64, expire time.Duration) (int64, error) {
-	pipe := h.Cli.Pipeline()
-	incrCmd := pipe.HIncrBy(ctx, h.Key, typex.ToString(field), increment)
-	if expire > 0 {
-		pipe.Expire(ctx, h.Key, expire)
-	}
-	_, err := pipe.Exec(ctx)
+	ctx = h.withOperation(ctx, "Incr")
+	var incrCmd *redis.IntCmd
+	err := h.opts.guard(func() error {
+		pipe := h.Cli.Pipeline()
+		incrCmd = pipe.HIncrBy(ctx, h.Key, typex.ToString(field), increment)
+		if expire > 0 {
+			pipe.Expire(ctx, h.Key, expire)
+		}
+		_, err := pipe.Exec(ctx)
+		return err
+	})
 	if err != nil {
 		return 0, err
 	}
 	return incrCmd.Val(), nil
 }
 
+// IncrMulti increments several fields by their delta in one pipeline and returns the new values.
+// The expire is applied once, after the increments
+func (h *HashMap[K, V]) IncrMulti(ctx context.Context, deltas map[K]int64, expire time.Duration) (map[K]int64, error) {
+	ctx = h.withOperation(ctx, "IncrMulti")
+	if len(deltas) == 0 {
+		return make(map[K]int64), nil
+	}
+
+	cmds := make(map[K]*redis.IntCmd, len(deltas))
+	err := h.opts.guard(func() error {
+		pipe := h.Cli.Pipeline()
+		for field, delta := range deltas {
+			cmds[field] = pipe.HIncrBy(ctx, h.Key, typex.ToString(field), delta)
+		}
+		if expire > 0 {
+			pipe.Expire(ctx, h.Key, expire)
+		}
+		_, err := pipe.Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[K]int64, len(cmds))
+	for field, cmd := range cmds {
+		result[field] = cmd.Val()
+	}
+	return result, nil
+}
+
 // IncrFloat increments the float value of a field by the given amount
 func (h *HashMap[K, V]) IncrFloat(ctx context.Context, field K, increment float64, expire time.Duration) (float64, error) {
-	pipe := h.Cli.Pipeline()
-	incrCmd := pipe.HIncrByFloat(ctx, h.Key, typex.ToString(field), increment)
-	if expire > 0 {
-		pipe.Expire(ctx, h.Key, expire)
-	}
-	_, err := pipe.Exec(ctx)
+	ctx = h.withOperation(ctx, "IncrFloat")
+	var incrCmd *redis.FloatCmd
+	err := h.opts.guard(func() error {
+		pipe := h.Cli.Pipeline()
+		incrCmd = pipe.HIncrByFloat(ctx, h.Key, typex.ToString(field), increment)
+		if expire > 0 {
+			pipe.Expire(ctx, h.Key, expire)
+		}
+		_, err := pipe.Exec(ctx)
+		return err
+	})
 	if err != nil {
 		return 0, err
 	}
 	return incrCmd.Val(), nil
 }
+
+// GetByPattern returns the fields whose name matches the redis glob pattern match, e.g. "user:123:*"
+// The hash is walked with HSCAN MATCH, so the filtering happens on the server
+func (h *HashMap[K, V]) GetByPattern(ctx context.Context, match string) (map[K]V, error) {
+	ctx = h.withOperation(ctx, "GetByPattern")
+	result := make(map[K]V)
+	var cursor uint64
+	for {
+		var kvs []string
+		var next uint64
+		err := h.opts.retry(ctx, func() (err error) {
+			kvs, next, err = h.Cli.HScan(ctx, h.Key, cursor, match, scanCount).Result()
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for i := 0; i+1 < len(kvs); i += 2 {
+			key, err := typex.ToAnyE[K](kvs[i])
+			if err != nil {
+				return nil, err
+			}
+			val, err := h.Decode(kvs[i+1])
+			if err != nil {
+				return nil, err
+			}
+			result[key] = val
+		}
+
+		if next == 0 {
+			return result, nil
+		}
+		cursor = next
+	}
+}
+
+// Raw returns the client of the hash, for commands HashMap doesn't wrap. Encode, Decode, EncodeField
+// and DecodeField convert values and fields the way HashMap does, so raw and wrapped calls can be mixed
+func (h *HashMap[K, V]) Raw() redis.UniversalClient {
+	return h.Cli
+}
+
+// EncodeField converts field to its redis form
+func (h *HashMap[K, V]) EncodeField(field K) string {
+	return typex.ToString(field)
+}
+
+// DecodeField reverses EncodeField
+func (h *HashMap[K, V]) DecodeField(s string) (K, error) {
+	return typex.ToAnyE[K](s)
+}
+
+// Encode converts value to its redis form, compressed when WithCompression is set,
+// which is also why it can fail
+func (h *HashMap[K, V]) Encode(value V) (string, error) {
+	return h.opts.encodeValue(typex.ToString(value))
+}
+
+// Decode reverses Encode
+func (h *HashMap[K, V]) Decode(s string) (V, error) {
+	s, err := h.opts.decodeValue(s)
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+	return typex.ToAnyE[V](s)
+}
+
+// withOperation names the redis spans of the current call, see WithSpanNames
+func (h *HashMap[K, V]) withOperation(ctx context.Context, op string) context.Context {
+	return h.opts.withOperation(ctx, "hashmap", op, h.Key)
+}
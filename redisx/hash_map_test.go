@@ -0,0 +1,194 @@
+package redisx
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashMap_IncrMulti(t *testing.T) {
+	s, cli := newTestClient(t)
+	ctx := context.Background()
+	h := NewHashMap[string, int64](cli, "counters")
+
+	_, err := h.Incr(ctx, "views", 10, 0)
+	assert.NoError(t, err)
+
+	got, err := h.IncrMulti(ctx, map[string]int64{"views": 5, "clicks": 2, "errors": -1}, time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{"views": 15, "clicks": 2, "errors": -1}, got)
+	assert.Equal(t, time.Minute, s.TTL("counters"))
+
+	got, err = h.IncrMulti(ctx, nil, time.Minute)
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestHashMap_SetIfChanged(t *testing.T) {
+	s, cli := newTestClient(t)
+	ctx := context.Background()
+	h := NewHashMap[string, int](cli, "cache")
+
+	written, err := h.SetIfChanged(ctx, "a", 1, time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, written)
+
+	s.FastForward(30 * time.Second)
+	written, err = h.SetIfChanged(ctx, "a", 1, time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, written)
+	assert.Equal(t, 30*time.Second, s.TTL("cache"))
+
+	written, err = h.SetIfChanged(ctx, "a", 2, time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, written)
+	assert.Equal(t, time.Minute, s.TTL("cache"))
+
+	v, err := h.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, v)
+}
+
+func TestHashMap_ExistsMulti(t *testing.T) {
+	_, cli := newTestClient(t)
+	ctx := context.Background()
+	h := NewHashMap[int, string](cli, "perms")
+	assert.NoError(t, h.SetMulti(ctx, map[int]string{1: "read", 3: "write"}, 0))
+
+	got, err := h.ExistsMulti(ctx, []int{1, 2, 3})
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]bool{1: true, 2: false, 3: true}, got)
+}
+
+func TestHashMap_SetKeepTTL(t *testing.T) {
+	s, cli := newTestClient(t)
+	ctx := context.Background()
+	h := NewHashMap[string, string](cli, "cache")
+
+	assert.NoError(t, h.SetKeepTTL(ctx, "a", "1", time.Minute))
+	assert.Equal(t, time.Minute, s.TTL("cache"))
+
+	s.FastForward(40 * time.Second)
+	assert.NoError(t, h.SetKeepTTL(ctx, "b", "2", time.Minute))
+	assert.Equal(t, 20*time.Second, s.TTL("cache"))
+
+	got, err := h.Get(ctx, "b")
+	assert.NoError(t, err)
+	assert.Equal(t, "2", got)
+
+	s.FastForward(20 * time.Second)
+	assert.False(t, s.Exists("cache"))
+}
+
+func TestHashMap_SetMultiWithFieldTTL(t *testing.T) {
+	s, cli := newTestClient(t)
+	ctx := context.Background()
+	h := NewHashMap[string, string](cli, "session")
+
+	assert.NoError(t, h.SetMultiWithFieldTTL(ctx, map[string]FieldTTL[string]{
+		"token":   {Value: "abc", TTL: 10 * time.Second},
+		"user":    {Value: "bob", TTL: time.Minute},
+		"created": {Value: "now"},
+	}))
+
+	s.FastForward(20 * time.Second)
+	got, err := h.GetAll(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"user": "bob", "created": "now"}, got)
+}
+
+func TestHashMap_Apply(t *testing.T) {
+	s, cli := newTestClient(t)
+	ctx := context.Background()
+	h := NewHashMap[string, int](cli, "cache")
+	assert.NoError(t, h.SetMulti(ctx, map[string]int{"a": 1, "b": 2, "c": 3}, 0))
+
+	assert.NoError(t, h.Apply(ctx, map[string]int{"a": 10, "d": 4, "c": 30}, []string{"b", "c"}, time.Minute))
+	got, err := h.GetAll(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"a": 10, "d": 4}, got)
+	assert.Equal(t, time.Minute, s.TTL("cache"))
+
+	assert.NoError(t, h.Apply(ctx, nil, nil, time.Hour))
+	assert.Equal(t, time.Minute, s.TTL("cache"))
+}
+
+func TestHashMap_GetAllInto(t *testing.T) {
+	_, cli := newTestClient(t)
+	ctx := context.Background()
+	type config struct {
+		Addr     string        `mapstructure:"addr"`
+		PoolSize int           `mapstructure:"pool_size"`
+		Timeout  time.Duration `mapstructure:"timeout"`
+		Debug    bool
+		Tags     []string `mapstructure:"tags"`
+		Ignored  string   `mapstructure:"-"`
+		missing  string
+	}
+	h := NewHashMap[string, string](cli, "config:redis", WithCompression(0))
+	assert.NoError(t, h.SetMulti(ctx, map[string]string{
+		"addr":      "127.0.0.1:6379",
+		"pool_size": "32",
+		"timeout":   "1500000000",
+		"debug":     "true",
+		"tags":      `["a","b"]`,
+		"Ignored":   "x",
+		"unknown":   "y",
+	}, 0))
+
+	var cfg config
+	assert.NoError(t, h.GetAllInto(ctx, &cfg))
+	assert.Equal(t, config{Addr: "127.0.0.1:6379", PoolSize: 32, Timeout: 1500 * time.Millisecond, Debug: true, Tags: []string{"a", "b"}}, cfg)
+
+	assert.NoError(t, h.Set(ctx, "pool_size", "many", 0))
+	err := h.GetAllInto(ctx, &cfg)
+	assert.ErrorContains(t, err, "pool_size")
+	assert.Error(t, h.GetAllInto(ctx, cfg))
+}
+
+func TestHashMap_RawEncodeDecode(t *testing.T) {
+	_, cli := newTestClient(t)
+	ctx := context.Background()
+	h := NewHashMap[int, string](cli, "blobs", WithCompression(8))
+
+	val, err := h.Encode("a value long enough to be compressed")
+	assert.NoError(t, err)
+	assert.NoError(t, h.Raw().HSet(ctx, h.Key, h.EncodeField(7), val).Err())
+	got, err := h.Get(ctx, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, "a value long enough to be compressed", got)
+
+	fields, err := h.Raw().HKeys(ctx, h.Key).Result()
+	assert.NoError(t, err)
+	field, err := h.DecodeField(fields[0])
+	assert.NoError(t, err)
+	assert.Equal(t, 7, field)
+	decoded, err := h.Decode(val)
+	assert.NoError(t, err)
+	assert.Equal(t, "a value long enough to be compressed", decoded)
+}
+
+func TestHashMap_GetByPattern(t *testing.T) {
+	_, cli := newTestClient(t)
+	ctx := context.Background()
+	h := NewHashMap[string, int](cli, "sessions")
+
+	got, err := h.GetByPattern(ctx, "user:123:*")
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+
+	fields := map[string]int{"user:7:web": 0}
+	for i := 0; i < 150; i++ {
+		fields["user:123:"+strconv.Itoa(i)] = i
+	}
+	assert.NoError(t, h.SetMulti(ctx, fields, 0))
+
+	got, err = h.GetByPattern(ctx, "user:123:*")
+	assert.NoError(t, err)
+	assert.Len(t, got, 150)
+	assert.Equal(t, 42, got["user:123:42"])
+	assert.NotContains(t, got, "user:7:web")
+}
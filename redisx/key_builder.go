@@ -0,0 +1,67 @@
+package redisx
+
+import "strings"
+
+// keySeparator joins the parts of the keys built by KeyBuilder
+const keySeparator = ":"
+
+var keyPartEscaper = strings.NewReplacer(`\`, `\\`, keySeparator, `\`+keySeparator)
+
+// KeyBuilder builds colon-joined keys under a namespace, e.g.
+//
+//	kb := NewKeyBuilder("prod", "tenant42")
+//	kb.Key("user", "1")                 // prod:tenant42:user:1
+//	kb.Namespace("cache").Key("user")    // prod:tenant42:cache:user
+//
+// Colons and backslashes in the parts are escaped with a backslash, so a part can never
+// be mistaken for two and keys of different namespaces can't collide. The zero value has no namespace.
+type KeyBuilder struct {
+	prefix string
+}
+
+// NewKeyBuilder returns a builder namespaced under parts, typically the env and the tenant
+func NewKeyBuilder(parts ...string) KeyBuilder {
+	return KeyBuilder{}.Namespace(parts...)
+}
+
+// Namespace returns a builder whose namespace is the one of b followed by parts
+func (b KeyBuilder) Namespace(parts ...string) KeyBuilder {
+	if len(parts) == 0 {
+		return b
+	}
+	return KeyBuilder{prefix: b.Key(parts...)}
+}
+
+// Key returns the key made of the namespace of b followed by parts
+func (b KeyBuilder) Key(parts ...string) string {
+	var sb strings.Builder
+	sb.WriteString(b.prefix)
+	for _, part := range parts {
+		if sb.Len() > 0 {
+			sb.WriteString(keySeparator)
+		}
+		sb.WriteString(keyPartEscaper.Replace(part))
+	}
+	return sb.String()
+}
+
+// String returns the namespace of b
+func (b KeyBuilder) String() string {
+	return b.prefix
+}
+
+// WithKeyBuilder builds the key of NewZQueue and NewHashMap with b, the key passed to them
+// is used as the last part and escaped like the other ones
+func WithKeyBuilder(b KeyBuilder) Option {
+	return func(o *options) {
+		o.keyBuilder = &b
+	}
+}
+
+// buildKey returns key built with the WithKeyBuilder builder, key itself when there's none
+func (o options) buildKey(key string) string {
+	if o.keyBuilder == nil {
+		return key
+	}
+	return o.keyBuilder.Key(key)
+}
@@ -0,0 +1,33 @@
+package redisx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyBuilder(t *testing.T) {
+	kb := NewKeyBuilder("prod", "tenant42")
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "key", got: kb.Key("user", "1"), want: "prod:tenant42:user:1"},
+		{name: "namespace", got: kb.Namespace("cache").Key("user"), want: "prod:tenant42:cache:user"},
+		{name: "escaped colon", got: kb.Key("a:b"), want: `prod:tenant42:a\:b`},
+		{name: "escaped backslash", got: kb.Key(`a\`, "b"), want: `prod:tenant42:a\\:b`},
+		{name: "zero value", got: KeyBuilder{}.Key("user", "1"), want: "user:1"},
+		{name: "string", got: kb.String(), want: "prod:tenant42"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.got)
+		})
+	}
+
+	assert.NotEqual(t, kb.Key("a:b", "c"), kb.Key("a", "b:c"))
+	assert.Equal(t, "prod:tenant42:rank", NewZQueue[string](nil, "rank", false, WithKeyBuilder(kb)).Key)
+	assert.Equal(t, "prod:tenant42:users", NewHashMap[string, int](nil, "users", WithKeyBuilder(kb)).Key)
+}
@@ -28,7 +28,7 @@ func GetByClient[T any](ctx context.Context, cli redis.UniversalClient, key stri
 		if errors.Is(err, redis.Nil) {
 			return res, nil
 		}
-		return res, err
+		return res, wrapWrongType(err, key, "string")
 	}
 
 	return typex.ToAnyE[T](resStr)
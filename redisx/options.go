@@ -0,0 +1,124 @@
+package redisx
+
+import (
+	"fmt"
+	"time"
+)
+
+// Option configures optional behaviour of ZQueue and HashMap.
+type Option func(*options)
+
+type options struct {
+	batchSize   int
+	spanNames   bool
+	compress    bool
+	compressMin int
+
+	retryAttempts int
+	retryBackoff  time.Duration
+	breaker       Breaker
+
+	keyBuilder *KeyBuilder
+	clock      Clock
+
+	// key and keyType (the redis type name) describe the key of the instance in a *WrongTypeError
+	key     string
+	keyType string
+}
+
+func newOptions(opts ...Option) options {
+	var o options
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return o
+}
+
+// WithBatchSize splits bulk writes (ZQueue.AddMulti, HashMap.SetMulti) into chunks of
+// at most n members, each sent in its own pipeline flush. n <= 0 disables chunking.
+// It's also the page size of ZQueue.RangeByScoreEach.
+func WithBatchSize(n int) Option {
+	return func(o *options) {
+		o.batchSize = n
+	}
+}
+
+// WithCompression gzips HashMap values of at least minBytes bytes on writes and transparently
+// decompresses them on reads, minBytes <= 0 compresses every value. Compressed values are tagged
+// with a header, so hashes holding values written before the option was enabled read correctly.
+func WithCompression(minBytes int) Option {
+	return func(o *options) {
+		o.compress = true
+		o.compressMin = minBytes
+	}
+}
+
+// BatchError is returned by chunked bulk writes when one of the chunks fails.
+// Chunks before the failed one have already been written to redis.
+type BatchError struct {
+	Succeeded int // number of chunks written successfully
+	Total     int // total number of chunks
+	Err       error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("redisx: batch write failed after %d/%d chunks: %v", e.Succeeded, e.Total, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// ScoreMerge decides the score of a member that appears more than once in the input of ZQueue.AddMulti
+type ScoreMerge int
+
+const (
+	MergeLast ScoreMerge = iota // the last occurrence wins, the default
+	MergeMax                    // the highest score wins
+	MergeMin                    // the lowest score wins
+	MergeSum                    // the scores are added up
+)
+
+// AddOption configures a single ZQueue.AddMulti call
+type AddOption func(*addOptions)
+
+type addOptions struct {
+	merge ScoreMerge
+	gt    bool
+	lt    bool
+}
+
+func newAddOptions(opts ...AddOption) addOptions {
+	var o addOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return o
+}
+
+// WithScoreMerge merges duplicate members of the input before sending them to redis
+func WithScoreMerge(merge ScoreMerge) AddOption {
+	return func(o *addOptions) {
+		o.merge = merge
+	}
+}
+
+// WithGT only updates existing members when the new score is greater (ZADD GT),
+// new members are always added
+func WithGT() AddOption {
+	return func(o *addOptions) {
+		o.gt = true
+	}
+}
+
+// WithLT only updates existing members when the new score is less (ZADD LT),
+// new members are always added
+func WithLT() AddOption {
+	return func(o *addOptions) {
+		o.lt = true
+	}
+}
@@ -0,0 +1,74 @@
+package redisx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// WithRetry retries the covered ZQueue and HashMap calls up to attempts times in total when they
+// fail with a connection error, waiting backoff before each new attempt (doubled every time).
+// Replies of the server such as WRONGTYPE, redis.Nil and context errors are never retried, and
+// no attempt is started once ctx is done.
+//
+// Covered calls are the reads and the writes that can be sent twice safely:
+//   - ZQueue: Add, AddMulti (per chunk), Remove, RemoveMulti, the RangeByScore and RangeByRank
+//     families, Snapshot, Peek*, Count, CountByScore, Score, ScoresOf, MembersByPattern (per page),
+//     Histogram, ReplaceAll (per chunk), RemoveByPattern (per page), MinMax, RankPercentile
+//   - HashMap: Set, SetMulti (per chunk), SetIfChanged, Get, GetMulti, GetAll, Delete, Exists,
+//     ExistsMulti, Len, Keys, Values, GetByPattern (per page), SetKeepTTL, SetMultiWithFieldTTL,
+//     Apply, GetAllInto
+//
+// Increments, pops, RemoveReturn and Drain are never retried.
+func WithRetry(attempts int, backoff time.Duration) Option {
+	return func(o *options) {
+		o.retryAttempts = attempts
+		o.retryBackoff = backoff
+	}
+}
+
+// retry runs fn through guard, retrying it as configured by WithRetry
+func (o options) retry(ctx context.Context, fn func() error) error {
+	backoff := o.retryBackoff
+	for attempt := 1; ; attempt++ {
+		err := o.guard(fn)
+		if err == nil || attempt >= o.retryAttempts || !isRetryable(err) {
+			return err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// isRetryable reports whether err is a connection error, as opposed to a reply of the server
+func isRetryable(err error) bool {
+	if errors.Is(err, redis.Nil) || errors.Is(err, ErrCircuitOpen) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var redisErr redis.Error
+	if errors.As(err, &redisErr) {
+		return false
+	}
+	if errors.Is(err, redis.ErrPoolTimeout) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,78 @@
+package redisx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRetryable(t *testing.T) {
+	_, cli := newTestClient(t)
+	assert.NoError(t, cli.Set(context.Background(), "k", "v", 0).Err())
+	wrongType := cli.HGet(context.Background(), "k", "f").Err()
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil reply", err: redis.Nil},
+		{name: "wrongtype", err: wrongType},
+		{name: "canceled", err: context.Canceled},
+		{name: "eof", err: io.EOF, want: true},
+		{name: "pool timeout", err: redis.ErrPoolTimeout, want: true},
+		{name: "net error", err: &net.OpError{Op: "dial", Err: errors.New("refused")}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isRetryable(tt.err))
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	o := newOptions(WithRetry(3, time.Millisecond))
+
+	calls := 0
+	err := o.retry(context.Background(), func() error {
+		calls++
+		if calls < 3 {
+			return io.EOF
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	err = o.retry(context.Background(), func() error {
+		calls++
+		return io.EOF
+	})
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = o.retry(ctx, func() error {
+		calls++
+		return io.EOF
+	})
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, 1, calls)
+
+	calls = 0
+	err = newOptions().retry(context.Background(), func() error {
+		calls++
+		return io.EOF
+	})
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,185 @@
+package redisx
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// KeyIterator iterates over the keys returned by ScanKeys, node after node. A key may be returned
+// more than once when it's written during the scan, as with SCAN itself.
+//
+//	it, err := ScanKeys(ctx, cli, "leaderboard:*", 0)
+//	for it.Next(ctx) {
+//		key := it.Val()
+//	}
+//	err = it.Err()
+type KeyIterator struct {
+	nodes []redis.Cmdable
+	match string
+	count int64
+
+	cur *redis.ScanIterator
+	val string
+	err error
+}
+
+// ScanKeys returns an iterator over the keys matching the glob pattern match, e.g. "leaderboard:*".
+// count is the COUNT hint of SCAN, <= 0 uses the default. With a cluster client every master is
+// scanned in turn, with a ring every shard.
+func ScanKeys(ctx context.Context, cli redis.UniversalClient, match string, count int64) (*KeyIterator, error) {
+	if count <= 0 {
+		count = scanCount
+	}
+	nodes, err := scanNodes(ctx, cli)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyIterator{nodes: nodes, match: match, count: count}, nil
+}
+
+// scanNodes returns the nodes holding a part of the keyspace of cli
+func scanNodes(ctx context.Context, cli redis.UniversalClient) ([]redis.Cmdable, error) {
+	var (
+		mu    sync.Mutex
+		nodes []redis.Cmdable
+	)
+	collect := func(_ context.Context, node *redis.Client) error {
+		mu.Lock()
+		defer mu.Unlock()
+		nodes = append(nodes, node)
+		return nil
+	}
+
+	switch c := cli.(type) {
+	case *redis.ClusterClient:
+		if err := c.ForEachMaster(ctx, collect); err != nil {
+			return nil, err
+		}
+	case *redis.Ring:
+		if err := c.ForEachShard(ctx, collect); err != nil {
+			return nil, err
+		}
+	default:
+		nodes = append(nodes, cli)
+	}
+	return nodes, nil
+}
+
+// Next advances to the next key, it returns false when the scan is over or failed, see Err
+func (it *KeyIterator) Next(ctx context.Context) bool {
+	for it.err == nil {
+		if it.cur == nil {
+			if len(it.nodes) == 0 {
+				return false
+			}
+			it.cur = it.nodes[0].Scan(ctx, 0, it.match, it.count).Iterator()
+			it.nodes = it.nodes[1:]
+		}
+		if it.cur.Next(ctx) {
+			it.val = it.cur.Val()
+			return true
+		}
+		it.err = it.cur.Err()
+		it.cur = nil
+	}
+	return false
+}
+
+// Val returns the current key
+func (it *KeyIterator) Val() string {
+	return it.val
+}
+
+// Err returns the error that stopped the iteration, nil when it reached the end
+func (it *KeyIterator) Err() error {
+	return it.err
+}
+
+// ErrEmptyPrefix is returned by DeleteByPrefix for an empty prefix, which would delete every key
+var ErrEmptyPrefix = errors.New("redisx: DeleteByPrefix needs a non empty prefix")
+
+// deleteByPrefixPasses caps the scans of DeleteByPrefix, so keys written as fast as they're deleted
+// can't keep it running
+const deleteByPrefixPasses = 3
+
+// DeleteByPrefix deletes every key starting with prefix, e.g. "tenant:42:", and returns how many were
+// deleted. The keys are found with ScanKeys, so every master of a cluster is covered, and removed with
+// UNLINK, which frees the memory in the background, one pipeline per batch of keys. The scan is repeated
+// until it finds nothing, so keys written meanwhile are deleted too, but at most 3 times: keys still being
+// written after the third scan are left. It stops with ctx.Err() once ctx is done.
+// Glob characters in prefix match literally
+func DeleteByPrefix(ctx context.Context, cli redis.UniversalClient, prefix string) (int64, error) {
+	if prefix == "" {
+		return 0, ErrEmptyPrefix
+	}
+	match := escapeGlob(prefix) + "*"
+
+	var deleted int64
+	for pass := 1; ; pass++ {
+		if err := ctx.Err(); err != nil {
+			return deleted, err
+		}
+		it, err := ScanKeys(ctx, cli, match, 0)
+		if err != nil {
+			return deleted, err
+		}
+		found := false
+		batch := make([]string, 0, scanCount)
+		for it.Next(ctx) {
+			found = true
+			if batch = append(batch, it.Val()); len(batch) == scanCount {
+				n, err := unlinkKeys(ctx, cli, batch)
+				deleted += n
+				if err != nil {
+					return deleted, err
+				}
+				batch = batch[:0]
+			}
+		}
+		if err := it.Err(); err != nil {
+			return deleted, err
+		}
+		n, err := unlinkKeys(ctx, cli, batch)
+		deleted += n
+		if err != nil || !found || pass == deleteByPrefixPasses {
+			return deleted, err
+		}
+	}
+}
+
+// unlinkKeys unlinks keys with one UNLINK per key in a single pipeline, keys of different cluster
+// slots can't share a command
+func unlinkKeys(ctx context.Context, cli redis.UniversalClient, keys []string) (int64, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	cmds := make([]*redis.IntCmd, 0, len(keys))
+	_, err := cli.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			cmds = append(cmds, pipe.Unlink(ctx, key))
+		}
+		return nil
+	})
+	var n int64
+	for _, cmd := range cmds {
+		n += cmd.Val()
+	}
+	return n, err
+}
+
+// escapeGlob escapes the characters of s the glob patterns of SCAN MATCH treat specially
+func escapeGlob(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
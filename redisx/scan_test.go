@@ -0,0 +1,97 @@
+package redisx
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanKeys(t *testing.T) {
+	mr, cli := newTestClient(t)
+	ctx := context.Background()
+	for i := 0; i < 250; i++ {
+		assert.NoError(t, mr.Set("leaderboard:"+strconv.Itoa(i), "x"))
+	}
+	assert.NoError(t, mr.Set("other", "x"))
+
+	it, err := ScanKeys(ctx, cli, "leaderboard:*", 50)
+	assert.NoError(t, err)
+	seen := make(map[string]bool)
+	for it.Next(ctx) {
+		seen[it.Val()] = true
+	}
+	assert.NoError(t, it.Err())
+	assert.Len(t, seen, 250)
+	assert.False(t, seen["other"])
+}
+
+func TestDeleteByPrefix(t *testing.T) {
+	mr, cli := newTestClient(t)
+	ctx := context.Background()
+	for i := 0; i < 250; i++ {
+		assert.NoError(t, mr.Set("tenant:42:"+strconv.Itoa(i), "x"))
+	}
+	assert.NoError(t, mr.Set("tenant:420:a", "x"))
+	assert.NoError(t, mr.Set("tenant:*:a", "x"))
+	assert.NoError(t, mr.Set("tenant:7:a", "x"))
+
+	deleted, err := DeleteByPrefix(ctx, cli, "tenant:42:")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(250), deleted)
+	assert.ElementsMatch(t, []string{"tenant:420:a", "tenant:*:a", "tenant:7:a"}, mr.Keys())
+
+	// glob characters match literally
+	deleted, err = DeleteByPrefix(ctx, cli, "tenant:*")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	_, err = DeleteByPrefix(ctx, cli, "")
+	assert.ErrorIs(t, err, ErrEmptyPrefix)
+	assert.Len(t, mr.Keys(), 2)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = DeleteByPrefix(canceled, cli, "tenant:")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Len(t, mr.Keys(), 2)
+}
+
+// rewriteHook writes a key after every UNLINK pipeline, like a writer as fast as DeleteByPrefix
+type rewriteHook struct {
+	mr *miniredis.Miniredis
+	n  int
+}
+
+func (h *rewriteHook) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (h *rewriteHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook { return next }
+
+func (h *rewriteHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		err := next(ctx, cmds)
+		if cmds[0].Name() == "unlink" {
+			h.n++
+			_ = h.mr.Set("job:new:"+strconv.Itoa(h.n), "x")
+		}
+		return err
+	}
+}
+
+func TestDeleteByPrefixConstantWrites(t *testing.T) {
+	mr, cli := newTestClient(t)
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, mr.Set("job:"+strconv.Itoa(i), "x"))
+	}
+	cli.AddHook(&rewriteHook{mr: mr})
+
+	// every pass finds the key written by the previous one, the passes are capped
+	deleted, err := DeleteByPrefix(ctx, cli, "job:")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10+deleteByPrefixPasses-1), deleted)
+	assert.Len(t, mr.Keys(), 1)
+}
@@ -0,0 +1,73 @@
+package redisx
+
+import (
+	"context"
+	"time"
+
+	"github.com/mbeoliero/kit/utils/typex"
+	"github.com/redis/go-redis/v9"
+)
+
+// Scheduler schedules jobs at a time on top of a ZQueue scored by the Unix milliseconds of the time
+// the jobs are due, e.g. retries at now + backoff polled by workers. Now is the time of the clock of
+// the queue, see WithClock.
+type Scheduler[T any] struct {
+	Queue *ZQueue[T]
+}
+
+func NewScheduler[T any](cli redis.UniversalClient, key string, opts ...Option) *Scheduler[T] {
+	return &Scheduler[T]{Queue: NewZQueue[T](cli, key, false, opts...)}
+}
+
+// Schedule schedules job at runAt, a job already scheduled is moved to runAt
+func (s *Scheduler[T]) Schedule(ctx context.Context, job T, runAt time.Time) error {
+	return s.Queue.Add(ctx, job, runAt.UnixMilli(), 0)
+}
+
+// Reschedule moves job to nextRunAt, e.g. after a failed run returned by Due
+func (s *Scheduler[T]) Reschedule(ctx context.Context, job T, nextRunAt time.Time) error {
+	return s.Queue.Add(ctx, job, nextRunAt.UnixMilli(), 0)
+}
+
+// claimDueScript removes and returns up to ARGV[2] members scored at most ARGV[1], the lowest first
+var claimDueScript = redis.NewScript(`
+local due = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1], "LIMIT", 0, ARGV[2])
+if #due > 0 then
+	redis.call("ZREM", KEYS[1], unpack(due))
+end
+return due
+`)
+
+// Due removes and returns up to limit jobs due by now, the earliest first. The jobs are claimed
+// atomically, so concurrent workers never get the same job; a job that has to run again must be
+// scheduled again
+func (s *Scheduler[T]) Due(ctx context.Context, limit int) ([]T, error) {
+	q := s.Queue
+	ctx = q.withOperation(ctx, "Due")
+	if limit <= 0 {
+		return []T{}, nil
+	}
+	var members []string
+	err := q.opts.guard(func() (err error) {
+		members, err = claimDueScript.Run(ctx, q.Cli, []string{q.Key}, q.opts.now().UnixMilli(), limit).StringSlice()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the jobs are claimed already, the ones that decode are handed out along with the first decode error
+	jobs := make([]T, 0, len(members))
+	var firstErr error
+	for _, m := range members {
+		job, err := typex.ToAnyE[T](m)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = &DecodeError{Member: m, Err: err}
+			}
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, firstErr
+}
@@ -0,0 +1,39 @@
+package redisx
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduler(t *testing.T) {
+	_, cli := newTestClient(t)
+	ctx := context.Background()
+	now := time.UnixMilli(1700000000000)
+	s := NewScheduler[int64](cli, "retries", WithClock(ClockFunc(func() time.Time { return now })))
+
+	assert.NoError(t, s.Schedule(ctx, 1, now.Add(-time.Second)))
+	assert.NoError(t, s.Schedule(ctx, 2, now.Add(-2*time.Second)))
+	assert.NoError(t, s.Schedule(ctx, 3, now))
+	assert.NoError(t, s.Schedule(ctx, 4, now.Add(time.Minute)))
+
+	due, err := s.Due(ctx, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{2, 1}, due)
+
+	due, err = s.Due(ctx, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3}, due)
+
+	assert.NoError(t, s.Reschedule(ctx, 3, now.Add(time.Second)))
+	due, err = s.Due(ctx, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, due)
+
+	now = now.Add(time.Minute)
+	due, err = s.Due(ctx, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3, 4}, due)
+}
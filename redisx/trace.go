@@ -0,0 +1,80 @@
+package redisx
+
+import (
+	"context"
+	"net"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type operationKey struct{}
+
+// WithOperation attaches a logical operation name to ctx, SpanNameHook uses it as
+// the name of the redis spans created under ctx. An existing name is kept so the
+// outermost operation wins.
+func WithOperation(ctx context.Context, name string) context.Context {
+	if _, ok := OperationFromContext(ctx); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, operationKey{}, name)
+}
+
+// OperationFromContext returns the logical operation name attached by WithOperation
+func OperationFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(operationKey{}).(string)
+	return name, ok
+}
+
+// WithSpanNames makes ZQueue and HashMap annotate every call with a logical name such as
+// "zqueue.RangeByScore leaderboard:daily". The name only shows up in traces when
+// SpanNameHook is installed after the otel tracing hook.
+func WithSpanNames() Option {
+	return func(o *options) {
+		o.spanNames = true
+	}
+}
+
+// SpanNameHook renames the span started by redisotel to the operation name found in ctx.
+// It must be added after redisotel.InstrumentTracing so it runs inside the otel span.
+type SpanNameHook struct{}
+
+var _ redis.Hook = SpanNameHook{}
+
+func (SpanNameHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (SpanNameHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		renameSpan(ctx)
+		return next(ctx, cmd)
+	}
+}
+
+func (SpanNameHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		renameSpan(ctx)
+		return next(ctx, cmds)
+	}
+}
+
+func renameSpan(ctx context.Context) {
+	name, ok := OperationFromContext(ctx)
+	if !ok {
+		return
+	}
+	if span := trace.SpanFromContext(ctx); span.IsRecording() {
+		span.SetName(name)
+	}
+}
+
+// withOperation annotates ctx with "<kind>.<op> <key>" when span naming is enabled
+func (o options) withOperation(ctx context.Context, kind, op, key string) context.Context {
+	if !o.spanNames {
+		return ctx
+	}
+	return WithOperation(ctx, kind+"."+op+" "+key)
+}
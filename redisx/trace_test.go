@@ -0,0 +1,85 @@
+package redisx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/redis/go-redis/extra/redisotel/v9"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTracedClient(t *testing.T) (redis.UniversalClient, *tracetest.SpanRecorder) {
+	t.Helper()
+	_, cli := newTestClient(t)
+	// open the pooled connection first so the handshake spans are not recorded
+	assert.NoError(t, cli.Ping(context.Background()).Err())
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+	assert.NoError(t, redisotel.InstrumentTracing(cli.(*redis.Client), redisotel.WithTracerProvider(tp)))
+	cli.AddHook(SpanNameHook{})
+	return cli, rec
+}
+
+func spanNames(rec *tracetest.SpanRecorder) []string {
+	var names []string
+	for _, s := range rec.Ended() {
+		names = append(names, s.Name())
+	}
+	return names
+}
+
+func TestWithOperation(t *testing.T) {
+	ctx := context.Background()
+	_, ok := OperationFromContext(ctx)
+	assert.False(t, ok)
+
+	ctx = WithOperation(ctx, "outer")
+	ctx = WithOperation(ctx, "inner")
+	name, ok := OperationFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "outer", name)
+
+	ctx = options{}.withOperation(context.Background(), "zqueue", "RangeByScore", "leaderboard:daily")
+	_, ok = OperationFromContext(ctx)
+	assert.False(t, ok)
+
+	ctx = options{spanNames: true}.withOperation(context.Background(), "zqueue", "RangeByScore", "leaderboard:daily")
+	name, _ = OperationFromContext(ctx)
+	assert.Equal(t, "zqueue.RangeByScore leaderboard:daily", name)
+}
+
+func TestSpanNameHook(t *testing.T) {
+	cli, rec := newTracedClient(t)
+	ctx := context.Background()
+
+	q := NewZQueue[string](cli, "leaderboard", false, WithSpanNames())
+	assert.NoError(t, q.Add(ctx, "a", 1, 0))
+	_, err := q.Count(ctx)
+	assert.NoError(t, err)
+
+	h := NewHashMap[string, int](cli, "profile", WithSpanNames())
+	assert.NoError(t, h.Set(ctx, "age", 30, 0))
+
+	assert.NoError(t, cli.Set(WithOperation(ctx, "custom"), "k", "v", 0).Err())
+
+	assert.Equal(t, []string{
+		"zqueue.Add leaderboard",
+		"zqueue.Count leaderboard",
+		"hashmap.Set profile",
+		"custom",
+	}, spanNames(rec))
+}
+
+func TestSpanNameHookDisabled(t *testing.T) {
+	cli, rec := newTracedClient(t)
+	ctx := context.Background()
+
+	q := NewZQueue[string](cli, "leaderboard", false)
+	_, err := q.Count(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"zcard"}, spanNames(rec))
+}
@@ -0,0 +1,76 @@
+package redisx
+
+import (
+	"context"
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrTxClient is returned by Tx.Exec when a write was queued from a structure on another client than the Tx one
+var ErrTxClient = errors.New("redisx: structure doesn't share the client of the tx")
+
+// Tx collects writes of several ZQueues and HashMaps sharing a client, queued with their *Tx methods
+// such as ZQueue.AddTx and HashMap.SetTx, and runs them together.
+// In cluster mode Exec needs all the keys in one slot, use a hash tag such as "{user:42}" in the keys.
+// Exec and ExecPipelined empty the Tx whatever their outcome, so it can be reused for the next batch
+type Tx struct {
+	cli redis.UniversalClient
+	ops []func(ctx context.Context, pipe redis.Pipeliner)
+	err error
+}
+
+// NewTx returns an empty Tx on cli
+func NewTx(cli redis.UniversalClient) *Tx {
+	return &Tx{cli: cli}
+}
+
+// queue records op, cli is the client of the structure queuing it
+func (t *Tx) queue(cli redis.UniversalClient, op func(ctx context.Context, pipe redis.Pipeliner)) {
+	if cli != t.cli {
+		t.fail(ErrTxClient)
+		return
+	}
+	t.ops = append(t.ops, op)
+}
+
+// fail keeps the first error met while queuing, Exec returns it without running anything
+func (t *Tx) fail(err error) {
+	if t.err == nil {
+		t.err = err
+	}
+}
+
+// Len returns the number of queued writes
+func (t *Tx) Len() int {
+	return len(t.ops)
+}
+
+// Exec runs the queued writes in a single MULTI/EXEC transaction, so readers see either none or all of them
+func (t *Tx) Exec(ctx context.Context) error {
+	return t.exec(ctx, t.cli.TxPipelined)
+}
+
+// ExecPipelined runs the queued writes in a single pipeline without MULTI/EXEC: one round trip,
+// no atomicity, and no single slot restriction in cluster mode
+func (t *Tx) ExecPipelined(ctx context.Context) error {
+	return t.exec(ctx, t.cli.Pipelined)
+}
+
+func (t *Tx) exec(ctx context.Context, run func(context.Context, func(redis.Pipeliner) error) ([]redis.Cmder, error)) error {
+	ops, err := t.ops, t.err
+	t.ops, t.err = nil, nil
+	if err != nil {
+		return err
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+	_, err = run(ctx, func(pipe redis.Pipeliner) error {
+		for _, op := range ops {
+			op(ctx, pipe)
+		}
+		return nil
+	})
+	return err
+}
@@ -0,0 +1,80 @@
+package redisx
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTx(t *testing.T) {
+	s, cli := newTestClient(t)
+	ctx := context.Background()
+	profiles := NewHashMap[string, string](cli, "profile:42")
+	board := NewZQueue[string](cli, "leaderboard", true)
+	assert.NoError(t, profiles.Set(ctx, "old", "x", 0))
+
+	tx := NewTx(cli)
+	profiles.SetTx(tx, "name", "ada", time.Hour)
+	profiles.DeleteTx(tx, "old")
+	board.AddTx(tx, "42", 1500, 0)
+	board.RemoveTx(tx)
+	assert.Equal(t, 3, tx.Len())
+	assert.NoError(t, tx.Exec(ctx))
+
+	all, err := profiles.GetAll(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"name": "ada"}, all)
+	assert.Equal(t, time.Hour, s.TTL(profiles.Key))
+	score, err := s.ZScore(board.Key, "42")
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1500), score)
+
+	tx = NewTx(cli)
+	board.RemoveTx(tx, "42")
+	assert.NoError(t, tx.ExecPipelined(ctx))
+	n, err := board.Count(ctx)
+	assert.NoError(t, err)
+	assert.Zero(t, n)
+
+	// a structure on another client makes the whole tx fail without running anything
+	_, other := newTestClient(t)
+	tx = NewTx(cli)
+	profiles.SetTx(tx, "name", "bob", 0)
+	NewZQueue[string](other, "leaderboard", true).AddTx(tx, "7", 1, 0)
+	assert.ErrorIs(t, tx.Exec(ctx), ErrTxClient)
+	name, err := profiles.Get(ctx, "name")
+	assert.NoError(t, err)
+	assert.Equal(t, "ada", name)
+}
+
+func TestTxReuse(t *testing.T) {
+	s, cli := newTestClient(t)
+	ctx := context.Background()
+	board := NewZQueue[string](cli, "leaderboard", true)
+
+	tx := NewTx(cli)
+	board.AddTx(tx, "a", 1, 0)
+	assert.NoError(t, tx.Exec(ctx))
+	assert.Zero(t, tx.Len())
+
+	// the ops of the first batch don't run again
+	assert.NoError(t, board.Remove(ctx, "a"))
+	board.AddTx(tx, "b", 2, 0)
+	assert.Equal(t, 1, tx.Len())
+	assert.NoError(t, tx.ExecPipelined(ctx))
+	members, err := s.ZMembers(board.Key)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b"}, members)
+
+	// a queuing error is reported once, the next batch starts clean
+	_, other := newTestClient(t)
+	NewZQueue[string](other, "leaderboard", true).AddTx(tx, "c", 3, 0)
+	assert.ErrorIs(t, tx.Exec(ctx), ErrTxClient)
+	board.AddTx(tx, "d", 4, 0)
+	assert.NoError(t, tx.Exec(ctx))
+	members, err = s.ZMembers(board.Key)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b", "d"}, members)
+}
@@ -2,8 +2,14 @@ package redisx
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strconv"
 	"time"
 
+	"github.com/mbeoliero/kit/log"
 	"github.com/mbeoliero/kit/utils/typex"
 	"github.com/redis/go-redis/v9"
 )
@@ -28,49 +34,110 @@ type ZQueue[T any] struct {
 	Key  string
 	Cli  redis.UniversalClient
 	Desc bool // true for descending order, false for ascending order
+
+	opts options
 }
 
-// redisZToElement from redis.Z to Element
-func redisZToElement[T any](z redis.Z) Element[T] {
-	return Element[T]{
-		Member: typex.ToAny[T](z.Member.(string)),
-		Score:  int64(z.Score),
+// redisZToElement from redis.Z to Element, a member that isn't a string or doesn't parse as T yields a *DecodeError
+func redisZToElement[T any](z redis.Z) (Element[T], error) {
+	str, ok := z.Member.(string)
+	if !ok {
+		return Element[T]{}, &DecodeError{Member: z.Member, Err: fmt.Errorf("unexpected member type %T", z.Member)}
+	}
+	member, err := typex.ToAnyE[T](str)
+	if err != nil {
+		return Element[T]{}, &DecodeError{Member: str, Err: err}
 	}
+	return Element[T]{Member: member, Score: int64(z.Score)}, nil
 }
 
-// redisZToElements from redis.Z slice to Element slice
-func redisZToElements[T any](zs []redis.Z) []Element[T] {
+// redisZToElements from redis.Z slice to Element slice. On a decode error the elements that did decode
+// are returned along with the first error, so the popping methods don't lose the elements already removed
+func redisZToElements[T any](zs []redis.Z) ([]Element[T], error) {
 	elements := make([]Element[T], 0, len(zs))
+	var firstErr error
 	for _, z := range zs {
-		elements = append(elements, redisZToElement[T](z))
+		elem, err := redisZToElement[T](z)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		elements = append(elements, elem)
 	}
-	return elements
+	return elements, firstErr
 }
 
-func NewZQueue[T any](cli redis.UniversalClient, key string, desc bool) *ZQueue[T] {
+func NewZQueue[T any](cli redis.UniversalClient, key string, desc bool, opts ...Option) *ZQueue[T] {
+	o := newOptions(opts...)
+	o.key, o.keyType = o.buildKey(key), "zset"
 	return &ZQueue[T]{
-		Key:  key,
+		Key:  o.key,
 		Cli:  cli,
 		Desc: desc,
+		opts: o,
 	}
 }
 
 // Add adds an element to the sorted set with the given score
 func (q *ZQueue[T]) Add(ctx context.Context, member T, score int64, expire time.Duration) error {
-	pipe := q.Cli.Pipeline()
-	pipe.ZAdd(ctx, q.Key, redis.Z{
-		Score:  float64(score),
-		Member: typex.ToString(member),
+	ctx = q.withOperation(ctx, "Add")
+	return q.opts.retry(ctx, func() error {
+		pipe := q.Cli.Pipeline()
+		pipe.ZAdd(ctx, q.Key, redis.Z{
+			Score:  float64(score),
+			Member: typex.ToString(member),
+		})
+		if expire > 0 {
+			pipe.Expire(ctx, q.Key, expire)
+		}
+		_, err := pipe.Exec(ctx)
+		return err
+	})
+}
+
+// addWithServerTimeScript scores ARGV[1] with the server time in milliseconds, applies the expire
+// ARGV[2] (ms) and returns the score. TIME is non-deterministic, hence the effects replication
+var addWithServerTimeScript = redis.NewScript(`
+redis.replicate_commands()
+local t = redis.call("TIME")
+local score = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+redis.call("ZADD", KEYS[1], score, ARGV[1])
+if tonumber(ARGV[2]) > 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return score
+`)
+
+// AddWithServerTime adds member scored with the redis server time in milliseconds and returns the score.
+// Producers on machines with skewed clocks still get a consistent order, as long as they share the server
+func (q *ZQueue[T]) AddWithServerTime(ctx context.Context, member T, expire time.Duration) (int64, error) {
+	ctx = q.withOperation(ctx, "AddWithServerTime")
+	var score int64
+	err := q.opts.guard(func() (err error) {
+		score, err = addWithServerTimeScript.Run(ctx, q.Cli, []string{q.Key}, typex.ToString(member), expire.Milliseconds()).Int64()
+		return err
 	})
-	if expire > 0 {
-		pipe.Expire(ctx, q.Key, expire)
+	if err != nil {
+		return 0, err
 	}
-	_, err := pipe.Exec(ctx)
-	return err
+	return score, nil
 }
 
 // AddMulti adds multiple elements to the sorted set
-func (q *ZQueue[T]) AddMulti(ctx context.Context, elements []Element[T], expire time.Duration) error {
+// By default a member appearing more than once in elements takes its last score, as ZADD does,
+// use WithScoreMerge to keep the max, min or sum instead, and WithGT/WithLT to compare with
+// the scores already in redis.
+// With WithBatchSize the elements are written in chunks, one pipeline flush per chunk,
+// and a failure is reported as *BatchError
+func (q *ZQueue[T]) AddMulti(ctx context.Context, elements []Element[T], expire time.Duration, opts ...AddOption) error {
+	ctx = q.withOperation(ctx, "AddMulti")
+	o := newAddOptions(opts...)
+	if o.gt && o.lt {
+		return errors.New("redisx: WithGT and WithLT are mutually exclusive")
+	}
+
 	members := make([]redis.Z, 0, len(elements))
 	for _, elem := range elements {
 		members = append(members, redis.Z{
@@ -78,78 +145,319 @@ func (q *ZQueue[T]) AddMulti(ctx context.Context, elements []Element[T], expire
 			Member: typex.ToString(elem.Member),
 		})
 	}
-	pipe := q.Cli.Pipeline()
-	pipe.ZAdd(ctx, q.Key, members...)
-	if expire > 0 {
-		pipe.Expire(ctx, q.Key, expire)
+	if o.merge != MergeLast {
+		members = mergeDuplicates(members, o.merge)
+	}
+
+	chunks := typex.Chunk(members, q.opts.batchSize)
+	for i, chunk := range chunks {
+		err := q.opts.retry(ctx, func() error {
+			pipe := q.Cli.Pipeline()
+			pipe.ZAddArgs(ctx, q.Key, redis.ZAddArgs{GT: o.gt, LT: o.lt, Members: chunk})
+			if expire > 0 {
+				pipe.Expire(ctx, q.Key, expire)
+			}
+			_, err := pipe.Exec(ctx)
+			return err
+		})
+		if err != nil {
+			if len(chunks) == 1 {
+				return err
+			}
+			return &BatchError{Succeeded: i, Total: len(chunks), Err: err}
+		}
+	}
+	return nil
+}
+
+// AddMultiNX adds the elements whose member isn't in the set yet with a single ZADD NX and returns
+// how many were added, members already present keep their score. The expire is applied in any case
+func (q *ZQueue[T]) AddMultiNX(ctx context.Context, elements []Element[T], expire time.Duration) (int64, error) {
+	ctx = q.withOperation(ctx, "AddMultiNX")
+	if len(elements) == 0 {
+		return 0, nil
+	}
+
+	members := make([]redis.Z, 0, len(elements))
+	for _, elem := range elements {
+		members = append(members, redis.Z{
+			Score:  float64(elem.Score),
+			Member: typex.ToString(elem.Member),
+		})
+	}
+
+	var addCmd *redis.IntCmd
+	err := q.opts.guard(func() error {
+		pipe := q.Cli.Pipeline()
+		addCmd = pipe.ZAddNX(ctx, q.Key, members...)
+		if expire > 0 {
+			pipe.Expire(ctx, q.Key, expire)
+		}
+		_, err := pipe.Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return addCmd.Val(), nil
+}
+
+// FromMap adds the members of m to q with their score through AddMulti, opts apply as they do there.
+// It's a function rather than a method since map keys need T to be comparable
+func FromMap[T comparable](ctx context.Context, q *ZQueue[T], m map[T]int64, expire time.Duration, opts ...AddOption) error {
+	if len(m) == 0 {
+		return nil
+	}
+	elements := make([]Element[T], 0, len(m))
+	for member, score := range m {
+		elements = append(elements, Element[T]{Member: member, Score: score})
+	}
+	return q.AddMulti(ctx, elements, expire, opts...)
+}
+
+// IncrMulti adds each delta to the score of its member with one pipeline of ZINCRBYs and returns the new scores,
+// members not in the set start from 0. The expire (> 0) is applied once after the increments.
+// It's a function rather than a method since map keys need T to be comparable
+func IncrMulti[T comparable](ctx context.Context, q *ZQueue[T], deltas map[T]int64, expire time.Duration) (map[T]int64, error) {
+	ctx = q.withOperation(ctx, "IncrMulti")
+	if len(deltas) == 0 {
+		return map[T]int64{}, nil
+	}
+
+	cmds := make(map[T]*redis.FloatCmd, len(deltas))
+	err := q.opts.guard(func() error {
+		pipe := q.Cli.Pipeline()
+		for member, delta := range deltas {
+			cmds[member] = pipe.ZIncrBy(ctx, q.Key, float64(delta), typex.ToString(member))
+		}
+		if expire > 0 {
+			pipe.Expire(ctx, q.Key, expire)
+		}
+		_, err := pipe.Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	scores := make(map[T]int64, len(cmds))
+	for member, cmd := range cmds {
+		scores[member] = int64(cmd.Val())
 	}
-	_, err := pipe.Exec(ctx)
-	return err
+	return scores, nil
+}
+
+// mergeDuplicates folds repeated members into one, keeping the position of the first occurrence
+func mergeDuplicates(members []redis.Z, merge ScoreMerge) []redis.Z {
+	index := make(map[interface{}]int, len(members))
+	merged := make([]redis.Z, 0, len(members))
+	for _, m := range members {
+		i, ok := index[m.Member]
+		if !ok {
+			index[m.Member] = len(merged)
+			merged = append(merged, m)
+			continue
+		}
+		switch merge {
+		case MergeMax:
+			merged[i].Score = max(merged[i].Score, m.Score)
+		case MergeMin:
+			merged[i].Score = min(merged[i].Score, m.Score)
+		case MergeSum:
+			merged[i].Score += m.Score
+		default:
+			merged[i].Score = m.Score
+		}
+	}
+	return merged
 }
 
 // Remove removes an element from the sorted set
 func (q *ZQueue[T]) Remove(ctx context.Context, member T) error {
-	return q.Cli.ZRem(ctx, q.Key, typex.ToString(member)).Err()
+	ctx = q.withOperation(ctx, "Remove")
+	return q.opts.retry(ctx, func() error {
+		return q.Cli.ZRem(ctx, q.Key, typex.ToString(member)).Err()
+	})
+}
+
+// AddTx queues the Add of member with score into tx, the expire (> 0) is queued right after it
+func (q *ZQueue[T]) AddTx(tx *Tx, member T, score int64, expire time.Duration) {
+	m := typex.ToString(member)
+	tx.queue(q.Cli, func(ctx context.Context, pipe redis.Pipeliner) {
+		pipe.ZAdd(ctx, q.Key, redis.Z{Score: float64(score), Member: m})
+		if expire > 0 {
+			pipe.Expire(ctx, q.Key, expire)
+		}
+	})
+}
+
+// RemoveTx queues the removal of members into tx
+func (q *ZQueue[T]) RemoveTx(tx *Tx, members ...T) {
+	if len(members) == 0 {
+		return
+	}
+	ms := make([]interface{}, 0, len(members))
+	for _, member := range members {
+		ms = append(ms, typex.ToString(member))
+	}
+	tx.queue(q.Cli, func(ctx context.Context, pipe redis.Pipeliner) {
+		pipe.ZRem(ctx, q.Key, ms...)
+	})
+}
+
+// removeReturnScript removes ARGV[1] from the set and returns its former score, nil when absent
+var removeReturnScript = redis.NewScript(`
+local score = redis.call("ZSCORE", KEYS[1], ARGV[1])
+if score then
+	redis.call("ZREM", KEYS[1], ARGV[1])
+end
+return score
+`)
+
+// RemoveReturn atomically removes member and returns it with its former score,
+// or nil, nil when it wasn't in the set. Among concurrent calls for the same member
+// exactly one gets the element
+func (q *ZQueue[T]) RemoveReturn(ctx context.Context, member T) (*Element[T], error) {
+	ctx = q.withOperation(ctx, "RemoveReturn")
+	var res string
+	err := q.opts.guard(func() (err error) {
+		res, err = removeReturnScript.Run(ctx, q.Cli, []string{q.Key}, typex.ToString(member)).Text()
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	score, err := strconv.ParseFloat(res, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &Element[T]{Member: member, Score: int64(score)}, nil
 }
 
 // RemoveMulti removes multiple elements from the sorted set
 func (q *ZQueue[T]) RemoveMulti(ctx context.Context, members []T) error {
+	ctx = q.withOperation(ctx, "RemoveMulti")
 	memberStrs := make([]interface{}, 0, len(members))
 	for _, member := range members {
 		memberStrs = append(memberStrs, typex.ToString(member))
 	}
-	return q.Cli.ZRem(ctx, q.Key, memberStrs...).Err()
+	return q.opts.retry(ctx, func() error {
+		return q.Cli.ZRem(ctx, q.Key, memberStrs...).Err()
+	})
 }
 
 // RangeByScore returns elements with scores between min and max
 // Respects the Desc field in ZQueue
+// A bound of -1 means -inf (min) or +inf (max), as in all the score based ranges taking int64 bounds,
+// so a legitimate score of -1 can't be used as a bound: use RangeBetween for sets holding negative scores
 func (q *ZQueue[T]) RangeByScore(ctx context.Context, minScore, maxScore int64) ([]Element[T], error) {
+	ctx = q.withOperation(ctx, "RangeByScore")
 	return q.rangeByScoreInternal(ctx, minScore, maxScore, 0, -1, q.Desc)
 }
 
 // RangeByScoreWithLimit returns elements with scores between min and max with pagination
 // Respects the Desc field in ZQueue
 func (q *ZQueue[T]) RangeByScoreWithLimit(ctx context.Context, minScore, maxScore int64, offset, count int64) ([]Element[T], error) {
+	ctx = q.withOperation(ctx, "RangeByScoreWithLimit")
 	return q.rangeByScoreInternal(ctx, minScore, maxScore, offset, count, q.Desc)
 }
 
+// RangeByScoreEach calls fn for each element with scores between min and max, stopping at the first error
+// fn returns, which is returned. The range is fetched in pages of WithBatchSize elements (100 by default),
+// so only one page is held in memory; elements added or removed meanwhile may be skipped or seen twice.
+// Respects the Desc field in ZQueue
+func (q *ZQueue[T]) RangeByScoreEach(ctx context.Context, minScore, maxScore int64, fn func(Element[T]) error) error {
+	ctx = q.withOperation(ctx, "RangeByScoreEach")
+	pageSize := int64(q.opts.batchSize)
+	if pageSize <= 0 {
+		pageSize = scanCount
+	}
+	for offset := int64(0); ; offset += pageSize {
+		elements, err := q.rangeByScoreInternal(ctx, minScore, maxScore, offset, pageSize, q.Desc)
+		if err != nil {
+			return err
+		}
+		for _, elem := range elements {
+			if err = fn(elem); err != nil {
+				return err
+			}
+		}
+		if int64(len(elements)) < pageSize {
+			return nil
+		}
+	}
+}
+
 // RangeFromScore returns elements with scores >= minScore
 // Respects the Desc field in ZQueue
 func (q *ZQueue[T]) RangeFromScore(ctx context.Context, minScore int64) ([]Element[T], error) {
+	ctx = q.withOperation(ctx, "RangeFromScore")
 	return q.rangeByScoreInternal(ctx, minScore, -1, 0, -1, q.Desc)
 }
 
 // RangeToScore returns elements with scores <= maxScore
 // Respects the Desc field in ZQueue
 func (q *ZQueue[T]) RangeToScore(ctx context.Context, maxScore int64) ([]Element[T], error) {
+	ctx = q.withOperation(ctx, "RangeToScore")
 	return q.rangeByScoreInternal(ctx, -1, maxScore, 0, -1, q.Desc)
 }
 
 // RangeByScoreRev returns elements with scores between min and max in reversed order
 // Reverses the Desc field in ZQueue
 func (q *ZQueue[T]) RangeByScoreRev(ctx context.Context, minScore, maxScore int64) ([]Element[T], error) {
+	ctx = q.withOperation(ctx, "RangeByScoreRev")
 	return q.rangeByScoreInternal(ctx, minScore, maxScore, 0, -1, !q.Desc)
 }
 
 // RangeByScoreWithLimitRev returns elements with scores between min and max with pagination in reversed order
 // Reverses the Desc field in ZQueue
 func (q *ZQueue[T]) RangeByScoreWithLimitRev(ctx context.Context, minScore, maxScore int64, offset, count int64) ([]Element[T], error) {
+	ctx = q.withOperation(ctx, "RangeByScoreWithLimitRev")
 	return q.rangeByScoreInternal(ctx, minScore, maxScore, offset, count, !q.Desc)
 }
 
 // RangeFromScoreRev returns elements with scores >= minScore in reversed order
 // Reverses the Desc field in ZQueue
 func (q *ZQueue[T]) RangeFromScoreRev(ctx context.Context, minScore int64) ([]Element[T], error) {
+	ctx = q.withOperation(ctx, "RangeFromScoreRev")
 	return q.rangeByScoreInternal(ctx, minScore, -1, 0, -1, !q.Desc)
 }
 
 // RangeToScoreRev returns elements with scores <= maxScore in reversed order
 // Reverses the Desc field in ZQueue
 func (q *ZQueue[T]) RangeToScoreRev(ctx context.Context, maxScore int64) ([]Element[T], error) {
+	ctx = q.withOperation(ctx, "RangeToScoreRev")
 	return q.rangeByScoreInternal(ctx, -1, maxScore, 0, -1, !q.Desc)
 }
 
+// RangeBetween returns elements with scores between min and max, a nil bound is unbounded (-inf/+inf).
+// Unlike RangeByScore every score, -1 included, is taken literally
+// Respects the Desc field in ZQueue
+func (q *ZQueue[T]) RangeBetween(ctx context.Context, minScore, maxScore *int64) ([]Element[T], error) {
+	ctx = q.withOperation(ctx, "RangeBetween")
+	return q.rangeByBounds(ctx, scoreBound(minScore, "-inf"), scoreBound(maxScore, "+inf"), 0, -1, q.Desc)
+}
+
+// RangeBetweenWithLimit is RangeBetween with pagination
+// Respects the Desc field in ZQueue
+func (q *ZQueue[T]) RangeBetweenWithLimit(ctx context.Context, minScore, maxScore *int64, offset, count int64) ([]Element[T], error) {
+	ctx = q.withOperation(ctx, "RangeBetweenWithLimit")
+	return q.rangeByBounds(ctx, scoreBound(minScore, "-inf"), scoreBound(maxScore, "+inf"), offset, count, q.Desc)
+}
+
+// scoreBound returns score as a ZRANGEBYSCORE bound, inf when it's nil
+func scoreBound(score *int64, inf string) string {
+	if score == nil {
+		return inf
+	}
+	return typex.ToString(*score)
+}
+
 // rangeByScoreInternal internal method to handle all range by score queries
+// A score of -1 stands for -inf (min) or +inf (max)
 func (q *ZQueue[T]) rangeByScoreInternal(ctx context.Context, minScore, maxScore int64, offset, count int64, desc bool) ([]Element[T], error) {
 	minS := "-inf"
 	if minScore != -1 {
@@ -160,98 +468,871 @@ func (q *ZQueue[T]) rangeByScoreInternal(ctx context.Context, minScore, maxScore
 	if maxScore != -1 {
 		maxS = typex.ToString(maxScore)
 	}
+	return q.rangeByBounds(ctx, minS, maxS, offset, count, desc)
+}
+
+// rangeByBounds runs ZRANGEBYSCORE (ZREVRANGEBYSCORE when desc) between the bounds minS and maxS
+func (q *ZQueue[T]) rangeByBounds(ctx context.Context, minS, maxS string, offset, count int64, desc bool) ([]Element[T], error) {
+	var zs []redis.Z
+	err := q.opts.retry(ctx, func() (err error) {
+		if desc {
+			zs, err = q.Cli.ZRevRangeByScoreWithScores(ctx, q.Key, &redis.ZRangeBy{
+				Min:    minS,
+				Max:    maxS,
+				Offset: offset,
+				Count:  count,
+			}).Result()
+		} else {
+			zs, err = q.Cli.ZRangeByScoreWithScores(ctx, q.Key, &redis.ZRangeBy{
+				Min:    minS,
+				Max:    maxS,
+				Offset: offset,
+				Count:  count,
+			}).Result()
+		}
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	return redisZToElements[T](zs)
+}
+
+// RankPercentile returns the elements whose rank falls within [lowPct, highPct) of the set, e.g.
+// 0, 0.1 for the top 10% when Desc is set. Respects the Desc field in ZQueue.
+// The band is half-open: rank r of a set of n elements is in it when lowPct <= r/n < highPct, so
+// adjacent bands such as [0, 0.1) and [0.1, 0.2) never share an element and together cover the set.
+// The bounds are computed from the ZCARD read just before the range, so elements added in between
+// may shift them. It fails unless 0 <= lowPct <= highPct <= 1
+func (q *ZQueue[T]) RankPercentile(ctx context.Context, lowPct, highPct float64) ([]Element[T], error) {
+	ctx = q.withOperation(ctx, "RankPercentile")
+	if !(0 <= lowPct && lowPct <= highPct && highPct <= 1) {
+		return nil, fmt.Errorf("redisx: invalid percentile band [%v, %v], want 0 <= low <= high <= 1", lowPct, highPct)
+	}
+
+	var count int64
+	err := q.opts.retry(ctx, func() (err error) {
+		count, err = q.Cli.ZCard(ctx, q.Key).Result()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	start := int64(math.Ceil(lowPct * float64(count)))
+	stop := int64(math.Ceil(highPct*float64(count))) - 1
+	if count == 0 || start > stop {
+		return []Element[T]{}, nil
+	}
+	return q.rangeByRankInternal(ctx, start, stop, q.Desc)
+}
+
+// Head returns the first n elements of the queue, the highest scores when Desc is set and the lowest
+// ones otherwise, in queue order. It returns every element when n exceeds the size and none when n <= 0
+func (q *ZQueue[T]) Head(ctx context.Context, n int64) ([]Element[T], error) {
+	ctx = q.withOperation(ctx, "Head")
+	if n <= 0 {
+		return []Element[T]{}, nil
+	}
+	return q.rangeByRankInternal(ctx, 0, n-1, q.Desc)
+}
+
+// Tail returns the last n elements of the queue in queue order, so its last element is the one Head
+// would return last for the whole queue. Respects the Desc field in ZQueue like Head
+func (q *ZQueue[T]) Tail(ctx context.Context, n int64) ([]Element[T], error) {
+	ctx = q.withOperation(ctx, "Tail")
+	if n <= 0 {
+		return []Element[T]{}, nil
+	}
+	return q.rangeByRankInternal(ctx, -n, -1, q.Desc)
+}
+
+// RangeByRankPage returns a page of pageSize elements ordered by rank, for cursor based pagination
+// Respects the Desc field in ZQueue
+// Pass 0 as pageToken for the first page and the returned nextToken for the following ones,
+// nextToken is 0 once the last page has been returned
+func (q *ZQueue[T]) RangeByRankPage(ctx context.Context, pageSize, pageToken int64) ([]Element[T], int64, error) {
+	ctx = q.withOperation(ctx, "RangeByRankPage")
+	if pageSize <= 0 {
+		return nil, 0, errors.New("redisx: page size must be positive")
+	}
+	if pageToken < 0 {
+		return nil, 0, errors.New("redisx: invalid page token")
+	}
+
+	// the token is the rank following the last one seen, fetch one extra element to detect the end
+	start := pageToken
+	elements, err := q.rangeByRankInternal(ctx, start, start+pageSize, q.Desc)
+	if err != nil {
+		return nil, 0, err
+	}
+	if int64(len(elements)) <= pageSize {
+		return elements, 0, nil
+	}
+	return elements[:pageSize], start + pageSize, nil
+}
 
+// rangeByRankInternal returns the elements between the ranks start and stop, both inclusive
+func (q *ZQueue[T]) rangeByRankInternal(ctx context.Context, start, stop int64, desc bool) ([]Element[T], error) {
 	var zs []redis.Z
-	var err error
-
-	if desc {
-		zs, err = q.Cli.ZRevRangeByScoreWithScores(ctx, q.Key, &redis.ZRangeBy{
-			Min:    minS,
-			Max:    maxS,
-			Offset: offset,
-			Count:  count,
-		}).Result()
-	} else {
-		zs, err = q.Cli.ZRangeByScoreWithScores(ctx, q.Key, &redis.ZRangeBy{
-			Min:    minS,
-			Max:    maxS,
-			Offset: offset,
-			Count:  count,
-		}).Result()
+	err := q.opts.retry(ctx, func() (err error) {
+		if desc {
+			zs, err = q.Cli.ZRevRangeWithScores(ctx, q.Key, start, stop).Result()
+		} else {
+			zs, err = q.Cli.ZRangeWithScores(ctx, q.Key, start, stop).Result()
+		}
+		return err
+	})
+
+	if err != nil {
+		return nil, err
 	}
+	return redisZToElements[T](zs)
+}
+
+// Peek returns the first element of the queue without removing it, the highest score when Desc
+// is set and the lowest one otherwise. Returns nil, nil when the queue is empty
+func (q *ZQueue[T]) Peek(ctx context.Context) (*Element[T], error) {
+	ctx = q.withOperation(ctx, "Peek")
+	return q.peek(ctx, q.Desc)
+}
+
+// PeekMin returns the element with the lowest score without removing it
+func (q *ZQueue[T]) PeekMin(ctx context.Context) (*Element[T], error) {
+	ctx = q.withOperation(ctx, "PeekMin")
+	return q.peek(ctx, false)
+}
 
+// PeekMax returns the element with the highest score without removing it
+func (q *ZQueue[T]) PeekMax(ctx context.Context) (*Element[T], error) {
+	ctx = q.withOperation(ctx, "PeekMax")
+	return q.peek(ctx, true)
+}
+
+// MinMax returns the elements with the lowest and the highest score in one round trip, both nil when the
+// queue is empty and the same element when it holds one. It ignores the Desc field
+func (q *ZQueue[T]) MinMax(ctx context.Context) (lowest, highest *Element[T], err error) {
+	ctx = q.withOperation(ctx, "MinMax")
+	var minCmd, maxCmd *redis.ZSliceCmd
+	err = q.opts.retry(ctx, func() error {
+		pipe := q.Cli.Pipeline()
+		minCmd = pipe.ZRangeWithScores(ctx, q.Key, 0, 0)
+		maxCmd = pipe.ZRangeWithScores(ctx, q.Key, -1, -1)
+		_, err := pipe.Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(minCmd.Val()) == 0 || len(maxCmd.Val()) == 0 {
+		return nil, nil, nil
+	}
+
+	lo, err := redisZToElement[T](minCmd.Val()[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	hi, err := redisZToElement[T](maxCmd.Val()[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	return &lo, &hi, nil
+}
+
+func (q *ZQueue[T]) peek(ctx context.Context, desc bool) (*Element[T], error) {
+	elements, err := q.rangeByRankInternal(ctx, 0, 0, desc)
 	if err != nil {
 		return nil, err
 	}
-	return redisZToElements[T](zs), nil
+	if len(elements) == 0 {
+		return nil, nil
+	}
+	return &elements[0], nil
+}
+
+// Snapshot returns all the elements of the set, respects the Desc field in ZQueue
+func (q *ZQueue[T]) Snapshot(ctx context.Context) ([]Element[T], error) {
+	ctx = q.withOperation(ctx, "Snapshot")
+	return q.rangeByRankInternal(ctx, 0, -1, q.Desc)
+}
+
+// ToMap returns all the elements of q as a member to score map, see FromMap
+func ToMap[T comparable](ctx context.Context, q *ZQueue[T]) (map[T]int64, error) {
+	ctx = q.withOperation(ctx, "ToMap")
+	elements, err := q.rangeByRankInternal(ctx, 0, -1, false)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[T]int64, len(elements))
+	for _, elem := range elements {
+		m[elem.Member] = elem.Score
+	}
+	return m, nil
+}
+
+// Drain returns all the elements of the set and deletes the key in one MULTI/EXEC transaction,
+// respects the Desc field in ZQueue. An empty or missing key returns an empty slice
+func (q *ZQueue[T]) Drain(ctx context.Context) ([]Element[T], error) {
+	ctx = q.withOperation(ctx, "Drain")
+	var rangeCmd *redis.ZSliceCmd
+	err := q.opts.guard(func() error {
+		_, err := q.Cli.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			if q.Desc {
+				rangeCmd = pipe.ZRevRangeWithScores(ctx, q.Key, 0, -1)
+			} else {
+				rangeCmd = pipe.ZRangeWithScores(ctx, q.Key, 0, -1)
+			}
+			pipe.Del(ctx, q.Key)
+			return nil
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return redisZToElements[T](rangeCmd.Val())
+}
+
+// replaceTempTTL bounds the life of the temp key of ReplaceAll, should it be left over by a crash
+const replaceTempTTL = time.Hour
+
+// ReplaceAll atomically replaces the whole set by elements: they are written to a temp key, in chunks
+// with WithBatchSize, which is then RENAMEd over the live key in a transaction, so readers see either
+// the old or the new set, never a partial one. Empty elements delete the set.
+// With a cluster client the key must carry a hash tag, e.g. "{leaderboard}:daily", so that the temp
+// key lands in the same slot
+func (q *ZQueue[T]) ReplaceAll(ctx context.Context, elements []Element[T], expire time.Duration) error {
+	ctx = q.withOperation(ctx, "ReplaceAll")
+	if len(elements) == 0 {
+		return q.opts.retry(ctx, func() error {
+			return q.Cli.Del(ctx, q.Key).Err()
+		})
+	}
+
+	tmpKey := q.Key + ":replace:" + strconv.FormatUint(rand.Uint64(), 36)
+	members := make([]redis.Z, 0, len(elements))
+	for _, elem := range elements {
+		members = append(members, redis.Z{
+			Score:  float64(elem.Score),
+			Member: typex.ToString(elem.Member),
+		})
+	}
+	for _, chunk := range typex.Chunk(members, q.opts.batchSize) {
+		err := q.opts.retry(ctx, func() error {
+			pipe := q.Cli.Pipeline()
+			pipe.ZAdd(ctx, tmpKey, chunk...)
+			pipe.Expire(ctx, tmpKey, replaceTempTTL)
+			_, err := pipe.Exec(ctx)
+			return err
+		})
+		if err != nil {
+			_ = q.Cli.Del(ctx, tmpKey).Err()
+			return err
+		}
+	}
+
+	return q.opts.guard(func() error {
+		_, err := q.Cli.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Rename(ctx, tmpKey, q.Key)
+			// the live key inherits the ttl of the temp key
+			if expire > 0 {
+				pipe.Expire(ctx, q.Key, expire)
+			} else {
+				pipe.Persist(ctx, q.Key)
+			}
+			return nil
+		})
+		return err
+	})
 }
 
 // PopMin removes and returns the element with the lowest score
 func (q *ZQueue[T]) PopMin(ctx context.Context) (*Element[T], error) {
-	zs, err := q.Cli.ZPopMin(ctx, q.Key, 1).Result()
+	ctx = q.withOperation(ctx, "PopMin")
+	var zs []redis.Z
+	err := q.opts.guard(func() (err error) {
+		zs, err = q.Cli.ZPopMin(ctx, q.Key, 1).Result()
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 	if len(zs) == 0 {
 		return nil, nil
 	}
-	elem := redisZToElement[T](zs[0])
+	elem, err := redisZToElement[T](zs[0])
+	if err != nil {
+		return nil, err
+	}
 	return &elem, nil
 }
 
 // PopMax removes and returns the element with the highest score
 func (q *ZQueue[T]) PopMax(ctx context.Context) (*Element[T], error) {
-	zs, err := q.Cli.ZPopMax(ctx, q.Key, 1).Result()
+	ctx = q.withOperation(ctx, "PopMax")
+	var zs []redis.Z
+	err := q.opts.guard(func() (err error) {
+		zs, err = q.Cli.ZPopMax(ctx, q.Key, 1).Result()
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 	if len(zs) == 0 {
 		return nil, nil
 	}
-	elem := redisZToElement[T](zs[0])
+	elem, err := redisZToElement[T](zs[0])
+	if err != nil {
+		return nil, err
+	}
 	return &elem, nil
 }
 
 // PopMinMulti removes and returns multiple elements with the lowest scores
 func (q *ZQueue[T]) PopMinMulti(ctx context.Context, count int64) ([]Element[T], error) {
-	zs, err := q.Cli.ZPopMin(ctx, q.Key, count).Result()
+	ctx = q.withOperation(ctx, "PopMinMulti")
+	var zs []redis.Z
+	err := q.opts.guard(func() (err error) {
+		zs, err = q.Cli.ZPopMin(ctx, q.Key, count).Result()
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
-	return redisZToElements[T](zs), nil
+	return redisZToElements[T](zs)
+}
+
+// BPopMinBatch removes and returns up to count elements with the lowest scores, blocking up to timeout
+// (BZPOPMIN, second precision, 0 blocks forever) while the set is empty. It returns an empty slice on timeout.
+// The first element is popped by BZPOPMIN and the others by a ZPOPMIN right after, so concurrent
+// consumers may split the due elements between them, but every element is popped exactly once
+func (q *ZQueue[T]) BPopMinBatch(ctx context.Context, count int64, timeout time.Duration) ([]Element[T], error) {
+	ctx = q.withOperation(ctx, "BPopMinBatch")
+	if count <= 0 {
+		return []Element[T]{}, nil
+	}
+	var first *redis.ZWithKey
+	err := q.opts.guard(func() (err error) {
+		first, err = q.Cli.BZPopMin(ctx, timeout, q.Key).Result()
+		return err
+	})
+	if errors.Is(err, redis.Nil) {
+		return []Element[T]{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if count == 1 {
+		return redisZToElements[T]([]redis.Z{first.Z})
+	}
+	var zs []redis.Z
+	err = q.opts.guard(func() (err error) {
+		zs, err = q.Cli.ZPopMin(ctx, q.Key, count-1).Result()
+		return err
+	})
+	if err != nil {
+		// the first element is already popped, hand it out along with the error
+		elements, _ := redisZToElements[T]([]redis.Z{first.Z})
+		return elements, err
+	}
+	return redisZToElements[T](append([]redis.Z{first.Z}, zs...))
 }
 
 // PopMaxMulti removes and returns multiple elements with the highest scores
 func (q *ZQueue[T]) PopMaxMulti(ctx context.Context, count int64) ([]Element[T], error) {
-	zs, err := q.Cli.ZPopMax(ctx, q.Key, count).Result()
+	ctx = q.withOperation(ctx, "PopMaxMulti")
+	var zs []redis.Z
+	err := q.opts.guard(func() (err error) {
+		zs, err = q.Cli.ZPopMax(ctx, q.Key, count).Result()
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
-	return redisZToElements[T](zs), nil
+	return redisZToElements[T](zs)
 }
 
 // RemoveRangeByScore removes elements with scores between min and max
 // Use "-inf" for min or "+inf" for max to represent infinity
 func (q *ZQueue[T]) RemoveRangeByScore(ctx context.Context, min, max string) (int64, error) {
-	return q.Cli.ZRemRangeByScore(ctx, q.Key, min, max).Result()
+	ctx = q.withOperation(ctx, "RemoveRangeByScore")
+	var n int64
+	err := q.opts.guard(func() (err error) {
+		n, err = q.Cli.ZRemRangeByScore(ctx, q.Key, min, max).Result()
+		return err
+	})
+	return n, err
 }
 
 // Count returns the number of elements in the sorted set
 func (q *ZQueue[T]) Count(ctx context.Context) (int64, error) {
-	return q.Cli.ZCard(ctx, q.Key).Result()
+	ctx = q.withOperation(ctx, "Count")
+	var count int64
+	err := q.opts.retry(ctx, func() (err error) {
+		count, err = q.Cli.ZCard(ctx, q.Key).Result()
+		return err
+	})
+	return count, err
+}
+
+// StartDepthGauge reports the number of elements to gaugeFn every interval, e.g. to set a Prometheus gauge,
+// from a goroutine running until ctx is done or stop is called. stop waits for the goroutine to exit.
+// A failing Count is logged and skips the sample, so the gauge keeps its last value, a panic of gaugeFn is
+// logged and ends the gauge. It fails unless interval is positive
+func (q *ZQueue[T]) StartDepthGauge(ctx context.Context, interval time.Duration, gaugeFn func(int64)) (stop func(), err error) {
+	if interval <= 0 {
+		return nil, errors.New("redisx: depth gauge interval must be positive")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer log.RecoverAndLog(ctx)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if n, err := q.Count(ctx); err != nil {
+				if ctx.Err() == nil {
+					log.CtxWarn(ctx, "redisx: counting %s for the depth gauge failed: %v", q.Key, err)
+				}
+			} else {
+				gaugeFn(n)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}, nil
+}
+
+// StartSweeper removes the elements scored at most olderThan(), e.g. a timestamp an hour ago, every interval
+// from a goroutine running until ctx is done or stop is called. stop waits for a running sweep to end.
+// Failing sweeps, and panics of olderThan, are logged and retried on the next tick. An interval <= 0 is
+// logged as an error and no sweeper is started, stop is then a no-op
+func (q *ZQueue[T]) StartSweeper(ctx context.Context, olderThan func() int64, interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		log.CtxError(ctx, "redisx: sweeper of %s not started, interval must be positive, got %v", q.Key, interval)
+		return func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			q.sweep(ctx, olderThan)
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// sweep runs a single tick of StartSweeper, a panic is logged so the next tick still runs
+func (q *ZQueue[T]) sweep(ctx context.Context, olderThan func() int64) {
+	defer log.RecoverAndLog(ctx)
+	upTo := strconv.FormatInt(olderThan(), 10)
+	if n, err := q.RemoveRangeByScore(ctx, "-inf", upTo); err != nil && ctx.Err() == nil {
+		log.CtxWarn(ctx, "redisx: sweeping %s up to %s failed: %v", q.Key, upTo, err)
+	} else if n > 0 {
+		log.CtxDebug(ctx, "redisx: swept %d elements of %s up to %s", n, q.Key, upTo)
+	}
+}
+
+// Observe streams the elements with a score greater than fromScore, in ascending (score, member) order,
+// polling every poll for those ordered after the last one sent. It's a change feed for queues scored by an
+// increasing value such as a timestamp or a sequence: an element added later with a score below the last
+// one sent is never sent, nor is one with an equal score and a member sorting before it, since redis
+// orders equal scores by member. Elements removed between two pages don't make it skip any.
+// Failed polls are logged and resume after the last element sent at the next tick, an element that
+// can't be decoded stops the feed there until it's removed. The channel is closed once ctx is done or
+// stop is called, stop waits for the polling goroutine to exit
+func (q *ZQueue[T]) Observe(ctx context.Context, fromScore int64, poll time.Duration) (<-chan Element[T], func(), error) {
+	if poll <= 0 {
+		return nil, nil, errors.New("redisx: observe poll interval must be positive")
+	}
+	ctx, cancel := context.WithCancel(q.withOperation(ctx, "Observe"))
+	ch := make(chan Element[T], scanCount)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer close(ch)
+		defer log.RecoverAndLog(ctx)
+		ticker := time.NewTicker(poll)
+		defer ticker.Stop()
+		cur := observeCursor{score: fromScore}
+		for {
+			var err error
+			if cur, err = q.observeOnce(ctx, cur, ch); err != nil && ctx.Err() == nil {
+				log.CtxWarn(ctx, "redisx: observing %s above %d failed: %v", q.Key, cur.score, err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return ch, func() {
+		cancel()
+		<-done
+	}, nil
+}
+
+// observeCursor is the last element Observe sent, ok is false until one was sent and the elements
+// scoring above score are the next ones
+type observeCursor struct {
+	score  int64
+	member string
+	ok     bool
+}
+
+// after reports whether z is ordered after the cursor
+func (c observeCursor) after(z redis.Z, member string) bool {
+	score := int64(z.Score)
+	return score > c.score || (c.ok && score == c.score && member > c.member)
+}
+
+// observeOnce sends the elements ordered after cur to ch, a page at a time, and returns the cursor of
+// the last one sent. Each page starts at the score of the cursor, the elements of that score up to the
+// cursor are read again and skipped: the page is grown by their number so it always brings new ones,
+// and removing elements between two pages can't make it skip any
+func (q *ZQueue[T]) observeOnce(ctx context.Context, cur observeCursor, ch chan<- Element[T]) (observeCursor, error) {
+	var seen int64 // the elements of the page start score known to be up to the cursor
+	for {
+		minS := strconv.FormatInt(cur.score, 10)
+		if !cur.ok {
+			minS = "(" + minS
+		}
+		count := seen + scanCount
+		var zs []redis.Z
+		err := q.opts.retry(ctx, func() (err error) {
+			zs, err = q.Cli.ZRangeByScoreWithScores(ctx, q.Key, &redis.ZRangeBy{Min: minS, Max: "+inf", Count: count}).Result()
+			return err
+		})
+		if err != nil {
+			return cur, err
+		}
+
+		seen = 0
+		for _, z := range zs {
+			member, _ := z.Member.(string)
+			if !cur.after(z, member) {
+				seen++
+				continue
+			}
+			e, err := redisZToElement[T](z)
+			if err != nil {
+				return cur, err
+			}
+			select {
+			case ch <- e:
+			case <-ctx.Done():
+				return cur, ctx.Err()
+			}
+			if e.Score != cur.score {
+				seen = 0
+			}
+			cur = observeCursor{score: e.Score, member: member, ok: true}
+			seen++
+		}
+		if int64(len(zs)) < count {
+			return cur, nil
+		}
+	}
 }
 
 // CountByScore returns the number of elements with scores between min and max
 // Use "-inf" for min or "+inf" for max to represent infinity
 func (q *ZQueue[T]) CountByScore(ctx context.Context, min, max string) (int64, error) {
-	return q.Cli.ZCount(ctx, q.Key, min, max).Result()
+	ctx = q.withOperation(ctx, "CountByScore")
+	var count int64
+	err := q.opts.retry(ctx, func() (err error) {
+		count, err = q.Cli.ZCount(ctx, q.Key, min, max).Result()
+		return err
+	})
+	return count, err
+}
+
+// Histogram returns the number of elements in each band of adjacent bounds of buckets, which must be
+// at least two strictly ascending scores: counts[i] covers buckets[i] <= score < buckets[i+1], the last
+// band includes its upper bound. The ZCOUNTs are sent in a single pipeline
+func (q *ZQueue[T]) Histogram(ctx context.Context, buckets []int64) ([]int64, error) {
+	ctx = q.withOperation(ctx, "Histogram")
+	if len(buckets) < 2 {
+		return nil, errors.New("redisx: histogram needs at least two bucket bounds")
+	}
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i] <= buckets[i-1] {
+			return nil, fmt.Errorf("redisx: histogram bucket bounds must be sorted ascending, got %d after %d", buckets[i], buckets[i-1])
+		}
+	}
+
+	counts := make([]int64, len(buckets)-1)
+	err := q.opts.retry(ctx, func() error {
+		pipe := q.Cli.Pipeline()
+		cmds := make([]*redis.IntCmd, len(counts))
+		for i := range counts {
+			maxS := "(" + typex.ToString(buckets[i+1])
+			if i == len(counts)-1 {
+				maxS = typex.ToString(buckets[i+1])
+			}
+			cmds[i] = pipe.ZCount(ctx, q.Key, typex.ToString(buckets[i]), maxS)
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return err
+		}
+		for i, cmd := range cmds {
+			counts[i] = cmd.Val()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return counts, nil
 }
 
 // Score returns the score of a member
 func (q *ZQueue[T]) Score(ctx context.Context, member T) (int64, error) {
-	score, err := q.Cli.ZScore(ctx, q.Key, typex.ToString(member)).Result()
+	ctx = q.withOperation(ctx, "Score")
+	var score float64
+	err := q.opts.retry(ctx, func() (err error) {
+		score, err = q.Cli.ZScore(ctx, q.Key, typex.ToString(member)).Result()
+		return err
+	})
 	if err != nil {
 		return 0, err
 	}
 	return int64(score), nil
 }
+
+// Lateness returns how overdue member is at now, now - score, for queues scored by due time.
+// A negative value means it isn't due yet. Returns ErrMemberNotFound when member isn't in the set
+func (q *ZQueue[T]) Lateness(ctx context.Context, member T, now int64) (int64, error) {
+	ctx = q.withOperation(ctx, "Lateness")
+	score, err := q.Score(ctx, member)
+	if errors.Is(err, redis.Nil) {
+		return 0, ErrMemberNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	return now - score, nil
+}
+
+// AverageLateness returns the average lateness at now of up to sample due members (score <= now),
+// the most overdue first. Returns 0 when no member is due
+func (q *ZQueue[T]) AverageLateness(ctx context.Context, now int64, sample int64) (int64, error) {
+	ctx = q.withOperation(ctx, "AverageLateness")
+	if sample <= 0 {
+		return 0, errors.New("redisx: sample must be positive")
+	}
+	elements, err := q.rangeByScoreInternal(ctx, -1, now, 0, sample, false)
+	if err != nil {
+		return 0, err
+	}
+	if len(elements) == 0 {
+		return 0, nil
+	}
+
+	var total int64
+	for _, elem := range elements {
+		total += now - elem.Score
+	}
+	return total / int64(len(elements)), nil
+}
+
+// ScoresOf returns the elements of members that are in the sorted set, in the order of members.
+// Absent members are skipped. ZMSCORE is sent as a raw command since redis.FloatSliceCmd
+// reports absent members as a zero score
+func (q *ZQueue[T]) ScoresOf(ctx context.Context, members []T) ([]Element[T], error) {
+	if len(members) == 0 {
+		return nil, nil
+	}
+	ctx = q.withOperation(ctx, "ScoresOf")
+	args := make([]any, 0, len(members)+2)
+	args = append(args, "zmscore", q.Key)
+	for _, m := range members {
+		args = append(args, typex.ToString(m))
+	}
+	var scores []any
+	err := q.opts.retry(ctx, func() (err error) {
+		scores, err = q.Cli.Do(ctx, args...).Slice()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	elements := make([]Element[T], 0, len(scores))
+	for i, s := range scores {
+		if s == nil || i >= len(members) {
+			continue
+		}
+		var score float64
+		switch v := s.(type) {
+		case float64:
+			score = v
+		case string:
+			if score, err = strconv.ParseFloat(v, 64); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unexpected zmscore reply type %T", s)
+		}
+		elements = append(elements, Element[T]{Member: members[i], Score: int64(score)})
+	}
+	return elements, nil
+}
+
+// MembersByPattern returns the elements whose member matches the redis glob pattern match, e.g. "user:*"
+// The set is walked with ZSCAN MATCH, so the filtering happens on the server. The order is not defined
+func (q *ZQueue[T]) MembersByPattern(ctx context.Context, match string) ([]Element[T], error) {
+	ctx = q.withOperation(ctx, "MembersByPattern")
+	return q.scanMembers(ctx, match, 0)
+}
+
+// FindMembers returns at most limit elements whose member matches the redis glob pattern match,
+// the scan stops as soon as limit matches are found. limit <= 0 returns all the matches, like MembersByPattern.
+// The order is not defined, successive calls may return different matches of a large set
+func (q *ZQueue[T]) FindMembers(ctx context.Context, match string, limit int) ([]Element[T], error) {
+	ctx = q.withOperation(ctx, "FindMembers")
+	return q.scanMembers(ctx, match, limit)
+}
+
+// RemoveByPattern removes the members matching the redis glob pattern match and returns how many were removed.
+// The set is walked with ZSCAN MATCH first, removing while scanning can make the cursor skip members,
+// then the matches are removed by a pipeline of ZREMs of at most WithBatchSize members each
+func (q *ZQueue[T]) RemoveByPattern(ctx context.Context, match string) (int64, error) {
+	ctx = q.withOperation(ctx, "RemoveByPattern")
+	var members []interface{}
+	var cursor uint64
+	for {
+		var kvs []string
+		var next uint64
+		err := q.opts.retry(ctx, func() (err error) {
+			kvs, next, err = q.Cli.ZScan(ctx, q.Key, cursor, match, scanCount).Result()
+			return err
+		})
+		if err != nil {
+			return 0, err
+		}
+		for i := 0; i < len(kvs); i += 2 {
+			members = append(members, kvs[i])
+		}
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+	if len(members) == 0 {
+		return 0, nil
+	}
+
+	var removed int64
+	err := q.opts.retry(ctx, func() error {
+		pipe := q.Cli.Pipeline()
+		var cmds []*redis.IntCmd
+		for _, chunk := range typex.Chunk(members, q.opts.batchSize) {
+			cmds = append(cmds, pipe.ZRem(ctx, q.Key, chunk...))
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return err
+		}
+		removed = 0
+		for _, cmd := range cmds {
+			removed += cmd.Val()
+		}
+		return nil
+	})
+	return removed, err
+}
+
+// scanMembers walks the set with ZSCAN MATCH until the end or limit (> 0) matches
+func (q *ZQueue[T]) scanMembers(ctx context.Context, match string, limit int) ([]Element[T], error) {
+	var elements []Element[T]
+	var cursor uint64
+	for {
+		var kvs []string
+		var next uint64
+		err := q.opts.retry(ctx, func() (err error) {
+			kvs, next, err = q.Cli.ZScan(ctx, q.Key, cursor, match, scanCount).Result()
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		zs, err := scanToZ(kvs)
+		if err != nil {
+			return nil, err
+		}
+		page, err := redisZToElements[T](zs)
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, page...)
+
+		if limit > 0 && len(elements) >= limit {
+			return elements[:limit], nil
+		}
+		if next == 0 {
+			return elements, nil
+		}
+		cursor = next
+	}
+}
+
+// Raw returns the client of the queue, for commands ZQueue doesn't wrap. Encode and Decode convert
+// members the way ZQueue does, so raw and wrapped calls can be mixed
+func (q *ZQueue[T]) Raw() redis.UniversalClient {
+	return q.Cli
+}
+
+// Encode converts member to its redis form
+func (q *ZQueue[T]) Encode(member T) string {
+	return typex.ToString(member)
+}
+
+// Decode reverses Encode, a member that doesn't parse as T yields a *DecodeError
+func (q *ZQueue[T]) Decode(s string) (T, error) {
+	member, err := typex.ToAnyE[T](s)
+	if err != nil {
+		return member, &DecodeError{Member: s, Err: err}
+	}
+	return member, nil
+}
+
+// withOperation names the redis spans of the current call, see WithSpanNames
+func (q *ZQueue[T]) withOperation(ctx context.Context, op string) context.Context {
+	return q.opts.withOperation(ctx, "zqueue", op, q.Key)
+}
+
+// scanToZ converts the member/score pairs returned by ZSCAN to redis.Z
+func scanToZ(kvs []string) ([]redis.Z, error) {
+	zs := make([]redis.Z, 0, len(kvs)/2)
+	for i := 0; i+1 < len(kvs); i += 2 {
+		score, err := strconv.ParseFloat(kvs[i+1], 64)
+		if err != nil {
+			return nil, err
+		}
+		zs = append(zs, redis.Z{Member: kvs[i], Score: score})
+	}
+	return zs, nil
+}
@@ -0,0 +1,952 @@
+package redisx
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestClient(t *testing.T) (*miniredis.Miniredis, redis.UniversalClient) {
+	t.Helper()
+	s := miniredis.RunT(t)
+	cli := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	t.Cleanup(func() { _ = cli.Close() })
+	return s, cli
+}
+
+func TestZQueue_RemoveReturn(t *testing.T) {
+	_, cli := newTestClient(t)
+	ctx := context.Background()
+	q := NewZQueue[string](cli, "queue", false)
+	assert.NoError(t, q.AddMulti(ctx, []Element[string]{{Member: "a", Score: 10}, {Member: "b", Score: -3}}, 0))
+
+	elem, err := q.RemoveReturn(ctx, "b")
+	assert.NoError(t, err)
+	assert.Equal(t, &Element[string]{Member: "b", Score: -3}, elem)
+
+	elem, err = q.RemoveReturn(ctx, "b")
+	assert.NoError(t, err)
+	assert.Nil(t, elem)
+
+	count, err := q.Count(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestZQueue_RemoveReturnConcurrent(t *testing.T) {
+	_, cli := newTestClient(t)
+	ctx := context.Background()
+	q := NewZQueue[int](cli, "queue", false)
+	assert.NoError(t, q.Add(ctx, 7, 42, 0))
+
+	var (
+		wg      sync.WaitGroup
+		removed atomic.Int32
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			elem, err := q.RemoveReturn(ctx, 7)
+			assert.NoError(t, err)
+			if elem != nil {
+				assert.Equal(t, int64(42), elem.Score)
+				removed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), removed.Load())
+}
+
+func TestZQueue_Drain(t *testing.T) {
+	_, cli := newTestClient(t)
+	ctx := context.Background()
+	q := NewZQueue[string](cli, "queue", true)
+
+	elements, err := q.Drain(ctx)
+	assert.NoError(t, err)
+	assert.NotNil(t, elements)
+	assert.Empty(t, elements)
+
+	assert.NoError(t, q.AddMulti(ctx, []Element[string]{{Member: "a", Score: 1}, {Member: "b", Score: 2}}, 0))
+	snapshot, err := q.Snapshot(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []Element[string]{{Member: "b", Score: 2}, {Member: "a", Score: 1}}, snapshot)
+
+	elements, err = q.Drain(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, snapshot, elements)
+
+	exists, err := cli.Exists(ctx, q.Key).Result()
+	assert.NoError(t, err)
+	assert.Zero(t, exists)
+}
+
+func TestZQueue_AddMultiNX(t *testing.T) {
+	_, cli := newTestClient(t)
+	ctx := context.Background()
+	q := NewZQueue[string](cli, "queue", false)
+	assert.NoError(t, q.Add(ctx, "a", 1, 0))
+
+	added, err := q.AddMultiNX(ctx, []Element[string]{{Member: "a", Score: 100}, {Member: "b", Score: 2}}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), added)
+
+	elements, err := q.Snapshot(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []Element[string]{{Member: "a", Score: 1}, {Member: "b", Score: 2}}, elements)
+}
+
+func TestZQueue_FromMapToMap(t *testing.T) {
+	_, cli := newTestClient(t)
+	ctx := context.Background()
+	q := NewZQueue[int](cli, "queue", true)
+	fixture := map[int]int64{1: 10, 2: -5, 3: 0}
+
+	assert.NoError(t, FromMap(ctx, q, fixture, 0))
+	got, err := ToMap(ctx, q)
+	assert.NoError(t, err)
+	assert.Equal(t, fixture, got)
+}
+
+func TestZQueue_Lateness(t *testing.T) {
+	_, cli := newTestClient(t)
+	ctx := context.Background()
+	q := NewZQueue[string](cli, "delayed", false)
+	assert.NoError(t, q.AddMulti(ctx, []Element[string]{{Member: "a", Score: 100}, {Member: "b", Score: 160}, {Member: "c", Score: 500}}, 0))
+
+	lateness, err := q.Lateness(ctx, "a", 200)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100), lateness)
+
+	_, err = q.Lateness(ctx, "missing", 200)
+	assert.ErrorIs(t, err, ErrMemberNotFound)
+
+	avg, err := q.AverageLateness(ctx, 200, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(70), avg)
+
+	avg, err = q.AverageLateness(ctx, 50, 10)
+	assert.NoError(t, err)
+	assert.Zero(t, avg)
+}
+
+func TestZQueue_FindMembers(t *testing.T) {
+	_, cli := newTestClient(t)
+	ctx := context.Background()
+	q := NewZQueue[string](cli, "members", false)
+	elements := make([]Element[string], 0, 300)
+	for i := 0; i < 300; i++ {
+		member := "order:" + strconv.Itoa(i)
+		if i%2 == 1 {
+			member = "user:" + strconv.Itoa(i)
+		}
+		elements = append(elements, Element[string]{Member: member, Score: int64(i)})
+	}
+	assert.NoError(t, q.AddMulti(ctx, elements, 0))
+
+	found, err := q.FindMembers(ctx, "user:*", 10)
+	assert.NoError(t, err)
+	assert.Len(t, found, 10)
+	for _, e := range found {
+		assert.True(t, strings.HasPrefix(e.Member, "user:"))
+	}
+
+	found, err = q.FindMembers(ctx, "user:*", 0)
+	assert.NoError(t, err)
+	assert.Len(t, found, 150)
+}
+
+func TestZQueue_AddWithServerTime(t *testing.T) {
+	mr, cli := newTestClient(t)
+	ctx := context.Background()
+	mr.SetTime(time.UnixMilli(1700000000123))
+	q := NewZQueue[string](cli, "jobs", false)
+
+	score, err := q.AddWithServerTime(ctx, "a", time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1700000000123), score)
+
+	got, err := q.Score(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, score, got)
+	assert.Equal(t, time.Minute, mr.TTL("jobs"))
+}
+
+func TestZQueue_RangeByScoreEach(t *testing.T) {
+	_, cli := newTestClient(t)
+	ctx := context.Background()
+	q := NewZQueue[int](cli, "wide", true, WithBatchSize(7))
+	elements := make([]Element[int], 0, 50)
+	for i := 0; i < 50; i++ {
+		elements = append(elements, Element[int]{Member: i, Score: int64(i)})
+	}
+	assert.NoError(t, q.AddMulti(ctx, elements, 0))
+
+	var got []int
+	assert.NoError(t, q.RangeByScoreEach(ctx, 10, 29, func(e Element[int]) error {
+		got = append(got, e.Member)
+		return nil
+	}))
+	assert.Len(t, got, 20)
+	assert.Equal(t, 29, got[0])
+	assert.Equal(t, 10, got[19])
+
+	stop := errors.New("stop")
+	calls := 0
+	err := q.RangeByScoreEach(ctx, 0, 49, func(e Element[int]) error {
+		calls++
+		if calls == 3 {
+			return stop
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 3, calls)
+}
+
+func TestZQueue_RangeBetweenNegativeScores(t *testing.T) {
+	_, cli := newTestClient(t)
+	ctx := context.Background()
+	q := NewZQueue[string](cli, "pre_epoch", false)
+	assert.NoError(t, q.AddMulti(ctx, []Element[string]{
+		{Member: "a", Score: -10},
+		{Member: "b", Score: -1},
+		{Member: "c", Score: 0},
+		{Member: "d", Score: 5},
+	}, 0))
+	score := func(s int64) *int64 { return &s }
+
+	// -1 is the infinity sentinel of RangeByScore: it returns a, b and c instead of b and c
+	got, err := q.RangeByScore(ctx, -1, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, ElementList[string](got).Members())
+
+	got, err = q.RangeBetween(ctx, score(-1), score(0))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b", "c"}, ElementList[string](got).Members())
+
+	got, err = q.RangeBetween(ctx, nil, score(-1))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, ElementList[string](got).Members())
+
+	got, err = q.RangeBetween(ctx, score(-5), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b", "c", "d"}, ElementList[string](got).Members())
+
+	got, err = q.RangeBetweenWithLimit(ctx, nil, nil, 1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b", "c"}, ElementList[string](got).Members())
+}
+
+func TestZQueue_Histogram(t *testing.T) {
+	_, cli := newTestClient(t)
+	ctx := context.Background()
+	q := NewZQueue[string](cli, "scores", false)
+	assert.NoError(t, q.AddMulti(ctx, []Element[string]{
+		{Member: "a", Score: 0}, {Member: "b", Score: 9}, {Member: "c", Score: 10},
+		{Member: "d", Score: 20}, {Member: "e", Score: 30},
+	}, 0))
+
+	counts, err := q.Histogram(ctx, []int64{0, 10, 20})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{2, 2}, counts)
+
+	_, err = q.Histogram(ctx, []int64{0, 20, 10})
+	assert.Error(t, err)
+	_, err = q.Histogram(ctx, []int64{0})
+	assert.Error(t, err)
+}
+
+func TestZQueue_BPopMinBatch(t *testing.T) {
+	_, cli := newTestClient(t)
+	ctx := context.Background()
+	q := NewZQueue[string](cli, "due", false)
+
+	got, err := q.BPopMinBatch(ctx, 3, time.Second)
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+	assert.NotNil(t, got)
+
+	assert.NoError(t, q.AddMulti(ctx, []Element[string]{{Member: "a", Score: 1}, {Member: "b", Score: 2}, {Member: "c", Score: 3}, {Member: "d", Score: 4}}, 0))
+	got, err = q.BPopMinBatch(ctx, 3, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, ElementList[string](got).Members())
+
+	got, err = q.BPopMinBatch(ctx, 3, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"d"}, ElementList[string](got).Members())
+}
+
+func TestZQueue_ReplaceAll(t *testing.T) {
+	mr, cli := newTestClient(t)
+	ctx := context.Background()
+	q := NewZQueue[string](cli, "leaderboard", true, WithBatchSize(10))
+
+	const size = 100
+	build := func(round int) []Element[string] {
+		elements := make([]Element[string], 0, size)
+		for i := 0; i < size; i++ {
+			elements = append(elements, Element[string]{Member: strconv.Itoa(round) + ":" + strconv.Itoa(i), Score: int64(i)})
+		}
+		return elements
+	}
+	assert.NoError(t, q.ReplaceAll(ctx, build(0), time.Minute))
+
+	var stop atomic.Bool
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for !stop.Load() {
+			n, err := q.Count(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, int64(size), n)
+		}
+	}()
+	for round := 1; round <= 20; round++ {
+		assert.NoError(t, q.ReplaceAll(ctx, build(round), time.Minute))
+	}
+	stop.Store(true)
+	wg.Wait()
+
+	got, err := q.Snapshot(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, got, size)
+	assert.Equal(t, "20:99", got[0].Member)
+	assert.Equal(t, time.Minute, mr.TTL("leaderboard"))
+	assert.Len(t, mr.Keys(), 1)
+
+	assert.NoError(t, q.ReplaceAll(ctx, nil, 0))
+	assert.False(t, mr.Exists("leaderboard"))
+}
+
+func TestZQueue_StartDepthGauge(t *testing.T) {
+	mr, cli := newTestClient(t)
+	ctx := context.Background()
+	q := NewZQueue[string](cli, "work", false)
+	assert.NoError(t, q.AddMulti(ctx, []Element[string]{{Member: "a", Score: 1}, {Member: "b", Score: 2}}, 0))
+
+	var depth atomic.Int64
+	depth.Store(-1)
+	_, err := q.StartDepthGauge(ctx, 0, depth.Store)
+	assert.Error(t, err)
+	stop, err := q.StartDepthGauge(ctx, 10*time.Millisecond, depth.Store)
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool { return depth.Load() == 2 }, time.Second, 5*time.Millisecond)
+
+	// errors skip the sample
+	mr.SetError("down")
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, int64(2), depth.Load())
+	mr.SetError("")
+
+	assert.NoError(t, q.Remove(ctx, "a"))
+	assert.Eventually(t, func() bool { return depth.Load() == 1 }, time.Second, 5*time.Millisecond)
+
+	stop()
+	assert.NoError(t, q.Remove(ctx, "b"))
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, int64(1), depth.Load())
+}
+
+func TestZQueue_RemoveByPattern(t *testing.T) {
+	_, cli := newTestClient(t)
+	ctx := context.Background()
+	q := NewZQueue[string](cli, "sessions", false, WithBatchSize(7))
+	elements := make([]Element[string], 0, 300)
+	for i := 0; i < 300; i++ {
+		prefix := "tmp:"
+		if i%3 == 0 {
+			prefix = "keep:"
+		}
+		elements = append(elements, Element[string]{Member: prefix + strconv.Itoa(i), Score: int64(i)})
+	}
+	assert.NoError(t, q.AddMulti(ctx, elements, 0))
+
+	removed, err := q.RemoveByPattern(ctx, "tmp:*")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(200), removed)
+
+	left, err := q.Snapshot(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, left, 100)
+	for _, e := range left {
+		assert.True(t, strings.HasPrefix(e.Member, "keep:"))
+	}
+}
+
+func TestZQueue_DecodeError(t *testing.T) {
+	// a member that isn't a string used to panic on the type assertion
+	_, err := redisZToElement[string](redis.Z{Member: 42, Score: 1})
+	assert.ErrorIs(t, err, ErrDecodeMember)
+
+	_, cli := newTestClient(t)
+	ctx := context.Background()
+	q := NewZQueue[int](cli, "ids", false)
+	assert.NoError(t, cli.ZAdd(ctx, q.Key, redis.Z{Member: "1", Score: 1}, redis.Z{Member: "oops", Score: 2}).Err())
+
+	elements, err := q.RangeByScore(ctx, 0, 10)
+	var decodeErr *DecodeError
+	assert.ErrorAs(t, err, &decodeErr)
+	assert.Equal(t, "oops", decodeErr.Member)
+	assert.Equal(t, []Element[int]{{Member: 1, Score: 1}}, elements)
+
+	// popped elements that decode are still handed out
+	elements, err = q.PopMinMulti(ctx, 2)
+	assert.ErrorIs(t, err, ErrDecodeMember)
+	assert.Equal(t, []Element[int]{{Member: 1, Score: 1}}, elements)
+}
+
+func TestIncrMulti(t *testing.T) {
+	s, cli := newTestClient(t)
+	ctx := context.Background()
+	q := NewZQueue[string](cli, "scores", false)
+	assert.NoError(t, q.Add(ctx, "a", 10, 0))
+
+	scores, err := IncrMulti(ctx, q, map[string]int64{"a": 5, "b": -3}, time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{"a": 15, "b": -3}, scores)
+	assert.Equal(t, time.Minute, s.TTL(q.Key))
+
+	scores, err = IncrMulti(ctx, q, map[string]int64{"b": 4}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{"b": 1}, scores)
+}
+
+func TestZQueue_StartSweeper(t *testing.T) {
+	mr, cli := newTestClient(t)
+	ctx := context.Background()
+	q := NewZQueue[string](cli, "sessions", false)
+	assert.NoError(t, q.AddMulti(ctx, []Element[string]{{Member: "old", Score: 10}, {Member: "new", Score: 100}}, 0))
+
+	var cutoff atomic.Int64
+	cutoff.Store(50)
+	stop := q.StartSweeper(ctx, cutoff.Load, 10*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		n, err := q.Count(ctx)
+		return err == nil && n == 1
+	}, time.Second, 5*time.Millisecond)
+
+	// a failing sweep is logged and the next tick tries again
+	mr.SetError("down")
+	time.Sleep(30 * time.Millisecond)
+	mr.SetError("")
+	cutoff.Store(100)
+	assert.Eventually(t, func() bool {
+		n, err := q.Count(ctx)
+		return err == nil && n == 0
+	}, time.Second, 5*time.Millisecond)
+
+	stop()
+	assert.NoError(t, q.Add(ctx, "late", 1, 0))
+	time.Sleep(30 * time.Millisecond)
+	n, err := q.Count(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+}
+
+func TestZQueue_StartSweeperInvalidInterval(t *testing.T) {
+	_, cli := newTestClient(t)
+	ctx := context.Background()
+	q := NewZQueue[string](cli, "sessions", false)
+	assert.NoError(t, q.Add(ctx, "old", 10, 0))
+
+	for _, interval := range []time.Duration{0, -time.Second} {
+		stop := q.StartSweeper(ctx, func() int64 { return 50 }, interval)
+		assert.NotNil(t, stop)
+		stop()
+	}
+	n, err := q.Count(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+}
+
+func TestZQueue_StartSweeperPanic(t *testing.T) {
+	_, cli := newTestClient(t)
+	ctx := context.Background()
+	q := NewZQueue[string](cli, "sessions", false)
+	assert.NoError(t, q.Add(ctx, "old", 10, 0))
+
+	// a panicking tick is logged, the next one still sweeps
+	var ticks atomic.Int32
+	stop := q.StartSweeper(ctx, func() int64 {
+		if ticks.Add(1) == 1 {
+			panic("clock broken")
+		}
+		return 50
+	}, 10*time.Millisecond)
+	defer stop()
+	assert.Eventually(t, func() bool {
+		n, err := q.Count(ctx)
+		return err == nil && n == 0
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestZQueue_Observe(t *testing.T) {
+	_, cli := newTestClient(t)
+	ctx := context.Background()
+	q := NewZQueue[int](cli, "events", false)
+
+	_, _, err := q.Observe(ctx, 0, 0)
+	assert.Error(t, err)
+
+	// more than a page at the same score is streamed in full
+	var initial []Element[int]
+	for i := 1; i <= 150; i++ {
+		initial = append(initial, Element[int]{Member: i, Score: 5})
+	}
+	assert.NoError(t, q.AddMulti(ctx, append(initial, Element[int]{Member: 0, Score: 1}), 0))
+
+	ch, stop, err := q.Observe(ctx, 1, 10*time.Millisecond)
+	assert.NoError(t, err)
+	receive := func(n int) []Element[int] {
+		var got []Element[int]
+		for len(got) < n {
+			select {
+			case e := <-ch:
+				got = append(got, e)
+			case <-time.After(time.Second):
+				t.Fatalf("received %d of %d elements", len(got), n)
+			}
+		}
+		return got
+	}
+	assert.ElementsMatch(t, initial, receive(150))
+
+	// only elements ordered after the last one sent, "99" at score 5, are sent
+	assert.NoError(t, q.Add(ctx, 200, 3, 0))
+	assert.NoError(t, q.Add(ctx, 1000, 5, 0))
+	assert.NoError(t, q.Add(ctx, 999, 5, 0))
+	assert.NoError(t, q.Add(ctx, 201, 7, 0))
+	assert.Equal(t, []Element[int]{{Member: 999, Score: 5}, {Member: 201, Score: 7}}, receive(2))
+
+	stop()
+	for range ch {
+		t.Fatal("no element expected after stop")
+	}
+}
+
+func TestZQueue_ObserveRemoveBetweenPages(t *testing.T) {
+	_, cli := newTestClient(t)
+	ctx := context.Background()
+	q := NewZQueue[int](cli, "events", false)
+
+	var all []Element[int]
+	for i := 1; i <= 250; i++ {
+		all = append(all, Element[int]{Member: i, Score: int64(i)})
+	}
+	assert.NoError(t, q.AddMulti(ctx, all, 0))
+
+	ch := make(chan Element[int])
+	done := make(chan observeCursor)
+	go func() {
+		cur, err := q.observeOnce(ctx, observeCursor{}, ch)
+		assert.NoError(t, err)
+		close(ch)
+		done <- cur
+	}()
+
+	// the first page is read, the elements sent so far are removed before the next one
+	got := []Element[int]{<-ch}
+	n, err := q.RemoveRangeByScore(ctx, "-inf", "50")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(50), n)
+	for e := range ch {
+		got = append(got, e)
+	}
+	assert.Equal(t, all, got)
+	assert.Equal(t, observeCursor{score: 250, member: "250", ok: true}, <-done)
+}
+
+func TestZQueue_MinMax(t *testing.T) {
+	_, cli := newTestClient(t)
+	ctx := context.Background()
+	q := NewZQueue[string](cli, "bounds", true)
+
+	lowest, highest, err := q.MinMax(ctx)
+	assert.NoError(t, err)
+	assert.Nil(t, lowest)
+	assert.Nil(t, highest)
+
+	assert.NoError(t, q.AddMulti(ctx, []Element[string]{{Member: "b", Score: 5}, {Member: "a", Score: -3}, {Member: "c", Score: 9}}, 0))
+	lowest, highest, err = q.MinMax(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, &Element[string]{Member: "a", Score: -3}, lowest)
+	assert.Equal(t, &Element[string]{Member: "c", Score: 9}, highest)
+}
+
+func TestZQueue_HeadTail(t *testing.T) {
+	_, cli := newTestClient(t)
+	ctx := context.Background()
+	asc := NewZQueue[int](cli, "queue", false)
+	desc := NewZQueue[int](cli, "queue", true)
+
+	head, err := asc.Head(ctx, 2)
+	assert.NoError(t, err)
+	assert.Empty(t, head)
+
+	assert.NoError(t, asc.AddMulti(ctx, []Element[int]{{Member: 1, Score: 1}, {Member: 2, Score: 2}, {Member: 3, Score: 3}}, 0))
+
+	head, err = asc.Head(ctx, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []Element[int]{{Member: 1, Score: 1}, {Member: 2, Score: 2}}, head)
+	tail, err := asc.Tail(ctx, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []Element[int]{{Member: 2, Score: 2}, {Member: 3, Score: 3}}, tail)
+
+	head, err = desc.Head(ctx, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []Element[int]{{Member: 3, Score: 3}, {Member: 2, Score: 2}}, head)
+	tail, err = desc.Tail(ctx, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []Element[int]{{Member: 2, Score: 2}, {Member: 1, Score: 1}}, tail)
+
+	// n larger than the set returns all of it
+	head, err = asc.Head(ctx, 10)
+	assert.NoError(t, err)
+	assert.Len(t, head, 3)
+	tail, err = desc.Tail(ctx, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []Element[int]{{Member: 3, Score: 3}, {Member: 2, Score: 2}, {Member: 1, Score: 1}}, tail)
+
+	tail, err = asc.Tail(ctx, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, tail)
+}
+
+func TestZQueue_RankPercentile(t *testing.T) {
+	_, cli := newTestClient(t)
+	ctx := context.Background()
+	q := NewZQueue[int](cli, "leaderboard", true)
+
+	elements, err := q.RankPercentile(ctx, 0, 0.1)
+	assert.NoError(t, err)
+	assert.Empty(t, elements)
+
+	var all []Element[int]
+	for i := 1; i <= 20; i++ {
+		all = append(all, Element[int]{Member: i, Score: int64(i)})
+	}
+	assert.NoError(t, q.AddMulti(ctx, all, 0))
+
+	top, err := q.RankPercentile(ctx, 0, 0.1)
+	assert.NoError(t, err)
+	assert.Equal(t, []Element[int]{{Member: 20, Score: 20}, {Member: 19, Score: 19}}, top)
+
+	// ranks 0 to 2 are below 0.12 of 20 elements
+	top, err = q.RankPercentile(ctx, 0, 0.12)
+	assert.NoError(t, err)
+	assert.Len(t, top, 3)
+
+	last, err := NewZQueue[int](cli, "leaderboard", false).RankPercentile(ctx, 0.9, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []Element[int]{{Member: 19, Score: 19}, {Member: 20, Score: 20}}, last)
+
+	empty, err := q.RankPercentile(ctx, 0.5, 0.5)
+	assert.NoError(t, err)
+	assert.Empty(t, empty)
+
+	_, err = q.RankPercentile(ctx, 0.5, 0.4)
+	assert.Error(t, err)
+	_, err = q.RankPercentile(ctx, -0.1, 0.4)
+	assert.Error(t, err)
+}
+
+func TestZQueue_RankPercentileBands(t *testing.T) {
+	_, cli := newTestClient(t)
+	ctx := context.Background()
+
+	for _, n := range []int{1, 7, 15, 30} {
+		q := NewZQueue[int](cli, "leaderboard:"+strconv.Itoa(n), true)
+		var all []Element[int]
+		for i := 0; i < n; i++ {
+			all = append(all, Element[int]{Member: i, Score: int64(i)})
+		}
+		assert.NoError(t, q.AddMulti(ctx, all, 0))
+
+		// adjacent bands partition the set: every member shows up in exactly one of them
+		seen := make(map[int]int)
+		for band := 0; band < 10; band++ {
+			elements, err := q.RankPercentile(ctx, float64(band)/10, float64(band+1)/10)
+			assert.NoError(t, err)
+			for _, e := range elements {
+				seen[e.Member]++
+			}
+		}
+		assert.Len(t, seen, n)
+		for member, times := range seen {
+			assert.Equal(t, 1, times, "member %d of %d", member, n)
+		}
+	}
+
+	// with 15 members the top 10% is ranks 0 and 1, the next 10% rank 2 only
+	q := NewZQueue[int](cli, "leaderboard:15", true)
+	top, err := q.RankPercentile(ctx, 0, 0.1)
+	assert.NoError(t, err)
+	assert.Equal(t, []Element[int]{{Member: 14, Score: 14}, {Member: 13, Score: 13}}, top)
+	next, err := q.RankPercentile(ctx, 0.1, 0.2)
+	assert.NoError(t, err)
+	assert.Equal(t, []Element[int]{{Member: 12, Score: 12}}, next)
+}
+
+func TestZQueue_RawEncodeDecode(t *testing.T) {
+	_, cli := newTestClient(t)
+	ctx := context.Background()
+	type job struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	q := NewZQueue[job](cli, "jobs", false)
+	assert.Equal(t, cli, q.Raw())
+
+	// a member written raw is read back by the wrapped calls and the other way round
+	assert.NoError(t, q.Raw().ZAdd(ctx, q.Key, redis.Z{Member: q.Encode(job{ID: 1, Name: "a"}), Score: 1}).Err())
+	assert.NoError(t, q.Add(ctx, job{ID: 2, Name: "b"}, 2, 0))
+	elements, err := q.Snapshot(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []Element[job]{{Member: job{ID: 1, Name: "a"}, Score: 1}, {Member: job{ID: 2, Name: "b"}, Score: 2}}, elements)
+
+	raw, err := q.Raw().ZRange(ctx, q.Key, 1, 1).Result()
+	assert.NoError(t, err)
+	member, err := q.Decode(raw[0])
+	assert.NoError(t, err)
+	assert.Equal(t, job{ID: 2, Name: "b"}, member)
+
+	_, err = q.Decode("{")
+	assert.ErrorIs(t, err, ErrDecodeMember)
+}
+
+func TestZQueue_AddMultiMerge(t *testing.T) {
+	_, cli := newTestClient(t)
+	ctx := context.Background()
+	q := NewZQueue[string](cli, "scores", false)
+	input := []Element[string]{{Member: "a", Score: 3}, {Member: "b", Score: 1}, {Member: "a", Score: 5}, {Member: "a", Score: 2}}
+
+	for _, tt := range []struct {
+		merge ScoreMerge
+		want  int64
+	}{
+		{MergeLast, 2},
+		{MergeMax, 5},
+		{MergeMin, 2},
+		{MergeSum, 10},
+	} {
+		assert.NoError(t, q.AddMulti(ctx, input, 0, WithScoreMerge(tt.merge)))
+		got, err := q.ScoresOf(ctx, []string{"a", "b"})
+		assert.NoError(t, err)
+		assert.Equal(t, []Element[string]{{Member: "a", Score: tt.want}, {Member: "b", Score: 1}}, got)
+	}
+
+	// the first occurrence keeps its position
+	assert.Equal(t, []redis.Z{{Member: "a", Score: 10}, {Member: "b", Score: 1}},
+		mergeDuplicates([]redis.Z{{Member: "a", Score: 3}, {Member: "b", Score: 1}, {Member: "a", Score: 7}}, MergeSum))
+
+	// GT and LT compare with the scores in redis, new members are added either way
+	assert.NoError(t, q.AddMulti(ctx, []Element[string]{{Member: "a", Score: 4}, {Member: "b", Score: 6}, {Member: "c", Score: 1}}, 0, WithGT()))
+	got, err := q.ScoresOf(ctx, []string{"a", "b", "c"})
+	assert.NoError(t, err)
+	assert.Equal(t, []Element[string]{{Member: "a", Score: 10}, {Member: "b", Score: 6}, {Member: "c", Score: 1}}, got)
+
+	assert.NoError(t, q.AddMulti(ctx, []Element[string]{{Member: "a", Score: 4}, {Member: "b", Score: 9}}, 0, WithLT(), WithScoreMerge(MergeMax)))
+	got, err = q.ScoresOf(ctx, []string{"a", "b"})
+	assert.NoError(t, err)
+	assert.Equal(t, []Element[string]{{Member: "a", Score: 4}, {Member: "b", Score: 6}}, got)
+
+	assert.Error(t, q.AddMulti(ctx, input, 0, WithGT(), WithLT()))
+}
+
+func TestZQueue_RangeByRankPage(t *testing.T) {
+	_, cli := newTestClient(t)
+	ctx := context.Background()
+	q := NewZQueue[string](cli, "leaderboard", true)
+	assert.NoError(t, q.AddMulti(ctx, []Element[string]{
+		{Member: "a", Score: 1}, {Member: "b", Score: 2}, {Member: "c", Score: 3}, {Member: "d", Score: 4}, {Member: "e", Score: 5},
+	}, 0))
+
+	page, next, err := q.RangeByRankPage(ctx, 2, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []Element[string]{{Member: "e", Score: 5}, {Member: "d", Score: 4}}, page)
+	assert.Equal(t, int64(2), next)
+
+	page, next, err = q.RangeByRankPage(ctx, 2, next)
+	assert.NoError(t, err)
+	assert.Equal(t, []Element[string]{{Member: "c", Score: 3}, {Member: "b", Score: 2}}, page)
+	assert.Equal(t, int64(4), next)
+
+	// the last page returns 0
+	page, next, err = q.RangeByRankPage(ctx, 2, next)
+	assert.NoError(t, err)
+	assert.Equal(t, []Element[string]{{Member: "a", Score: 1}}, page)
+	assert.Equal(t, int64(0), next)
+
+	// a last page that's exactly full returns 0 too
+	page, next, err = q.RangeByRankPage(ctx, 5, 0)
+	assert.NoError(t, err)
+	assert.Len(t, page, 5)
+	assert.Equal(t, int64(0), next)
+
+	_, _, err = q.RangeByRankPage(ctx, 0, 0)
+	assert.Error(t, err)
+	_, _, err = q.RangeByRankPage(ctx, 2, -1)
+	assert.Error(t, err)
+}
+
+func TestZQueue_MembersByPattern(t *testing.T) {
+	_, cli := newTestClient(t)
+	ctx := context.Background()
+	q := NewZQueue[string](cli, "online", false)
+
+	got, err := q.MembersByPattern(ctx, "user:*")
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+
+	var want []Element[string]
+	for i := 0; i < 150; i++ {
+		want = append(want, Element[string]{Member: "user:" + strconv.Itoa(i), Score: int64(i)})
+	}
+	assert.NoError(t, q.AddMulti(ctx, append(want, Element[string]{Member: "bot:1", Score: 1}), 0))
+
+	got, err = q.MembersByPattern(ctx, "user:*")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, want, got)
+}
+
+func TestZQueue_ScoresOf(t *testing.T) {
+	_, cli := newTestClient(t)
+	ctx := context.Background()
+	q := NewZQueue[int](cli, "leaderboard", true)
+
+	got, err := q.ScoresOf(ctx, []int{1, 2})
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+
+	assert.NoError(t, q.AddMulti(ctx, []Element[int]{{Member: 1, Score: 10}, {Member: 3, Score: 0}, {Member: 4, Score: -5}}, 0))
+
+	// the order of members is kept, absent members are skipped and a 0 score isn't taken for absence
+	got, err = q.ScoresOf(ctx, []int{4, 2, 1, 3, 5})
+	assert.NoError(t, err)
+	assert.Equal(t, []Element[int]{{Member: 4, Score: -5}, {Member: 1, Score: 10}, {Member: 3, Score: 0}}, got)
+
+	got, err = q.ScoresOf(ctx, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestZQueue_Peek(t *testing.T) {
+	_, cli := newTestClient(t)
+	ctx := context.Background()
+	asc := NewZQueue[string](cli, "jobs", false)
+	desc := NewZQueue[string](cli, "jobs", true)
+
+	for _, q := range []*ZQueue[string]{asc, desc} {
+		for _, peek := range []func(context.Context) (*Element[string], error){q.Peek, q.PeekMin, q.PeekMax} {
+			e, err := peek(ctx)
+			assert.NoError(t, err)
+			assert.Nil(t, e)
+		}
+	}
+
+	assert.NoError(t, asc.AddMulti(ctx, []Element[string]{{Member: "b", Score: 5}, {Member: "a", Score: 1}, {Member: "c", Score: 9}}, 0))
+	lowest, highest := &Element[string]{Member: "a", Score: 1}, &Element[string]{Member: "c", Score: 9}
+
+	// Peek honors Desc, PeekMin and PeekMax don't
+	for _, tt := range []struct {
+		q    *ZQueue[string]
+		peek *Element[string]
+	}{
+		{asc, lowest},
+		{desc, highest},
+	} {
+		e, err := tt.q.Peek(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.peek, e)
+		e, err = tt.q.PeekMin(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, lowest, e)
+		e, err = tt.q.PeekMax(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, highest, e)
+	}
+
+	n, err := asc.Count(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+}
+
+// failPipelineHook fails the pipelines following the first ok ones
+type failPipelineHook struct {
+	ok  int
+	n   int
+	err error
+}
+
+func (h *failPipelineHook) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (h *failPipelineHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook { return next }
+
+func (h *failPipelineHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if h.n++; h.n > h.ok {
+			return h.err
+		}
+		return next(ctx, cmds)
+	}
+}
+
+func TestWithBatchSize(t *testing.T) {
+	mr, cli := newTestClient(t)
+	ctx := context.Background()
+	q := NewZQueue[int](cli, "batched", false, WithBatchSize(10))
+	h := NewHashMap[int, int](cli, "batched:hash", WithBatchSize(10))
+
+	var elements []Element[int]
+	fields := map[int]int{}
+	for i := 0; i < 25; i++ {
+		elements = append(elements, Element[int]{Member: i, Score: int64(i)})
+		fields[i] = i
+	}
+	assert.NoError(t, q.AddMulti(ctx, elements, 0))
+	assert.NoError(t, h.SetMulti(ctx, fields, 0))
+	n, err := q.Count(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(25), n)
+	keys, err := mr.HKeys("batched:hash")
+	assert.NoError(t, err)
+	assert.Len(t, keys, 25)
+
+	// the third chunk fails, the error reports the two written
+	failed := errors.New("connection reset")
+	hook := &failPipelineHook{ok: 2, err: failed}
+	cli.AddHook(hook)
+	for _, write := range []func() error{
+		func() error { return q.AddMulti(ctx, elements, 0) },
+		func() error { return h.SetMulti(ctx, fields, 0) },
+	} {
+		hook.n = 0
+		err = write()
+		var batchErr *BatchError
+		assert.ErrorAs(t, err, &batchErr)
+		assert.Equal(t, 2, batchErr.Succeeded)
+		assert.Equal(t, 3, batchErr.Total)
+		assert.ErrorIs(t, err, failed)
+	}
+
+	// a single chunk fails with the error as-is
+	hook.n, hook.ok = 0, 0
+	err = NewZQueue[int](cli, "small", false, WithBatchSize(10)).AddMulti(ctx, elements[:5], 0)
+	assert.ErrorIs(t, err, failed)
+	var batchErr *BatchError
+	assert.False(t, errors.As(err, &batchErr))
+}
@@ -0,0 +1,16 @@
+package typex
+
+// Must returns v and panics when err is not nil, e.g. typex.Must(typex.ToAnyE[int]("42")).
+// Meant for tests, scripts and values known to be valid, not for production input.
+func Must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Ignore returns v and drops the error, v is whatever the callee returned with it,
+// usually the zero value of T. Like Must it's meant for tests and scripts.
+func Ignore[T any](v T, _ error) T {
+	return v
+}
@@ -0,0 +1,19 @@
+package typex
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMust(t *testing.T) {
+	assert.Equal(t, 42, Must(ToAnyE[int]("42")))
+	assert.Panics(t, func() {
+		Must(ToAnyE[int]("forty-two"))
+	})
+}
+
+func TestIgnore(t *testing.T) {
+	assert.Equal(t, 42, Ignore(ToAnyE[int]("42")))
+	assert.Equal(t, 0, Ignore(ToAnyE[int]("forty-two")))
+}
@@ -0,0 +1,56 @@
+package typex
+
+// Chunk splits s into consecutive chunks of size elements, the last chunk may be shorter.
+// A size <= 0 returns s as a single chunk, a nil or empty s returns nil.
+// The chunks share the backing array of s.
+func Chunk[T any](s []T, size int) [][]T {
+	if len(s) == 0 {
+		return nil
+	}
+	if size <= 0 || size >= len(s) {
+		return [][]T{s}
+	}
+
+	chunks := make([][]T, 0, (len(s)+size-1)/size)
+	for start := 0; start < len(s); start += size {
+		end := min(start+size, len(s))
+		chunks = append(chunks, s[start:end:end])
+	}
+	return chunks
+}
+
+// Flatten concatenates the slices of s into one slice, a nil s returns nil
+func Flatten[T any](s [][]T) []T {
+	if s == nil {
+		return nil
+	}
+
+	n := 0
+	for _, chunk := range s {
+		n += len(chunk)
+	}
+	flat := make([]T, 0, n)
+	for _, chunk := range s {
+		flat = append(flat, chunk...)
+	}
+	return flat
+}
+
+// ToMap indexes s by key. When several elements share a key the last one wins.
+func ToMap[T any, K comparable](s []T, key func(T) K) map[K]T {
+	m := make(map[K]T, len(s))
+	for _, v := range s {
+		m[key(v)] = v
+	}
+	return m
+}
+
+// GroupBy groups the elements of s by key, keeping their order within each group
+func GroupBy[T any, K comparable](s []T, key func(T) K) map[K][]T {
+	m := make(map[K][]T)
+	for _, v := range s {
+		k := key(v)
+		m[k] = append(m[k], v)
+	}
+	return m
+}
@@ -0,0 +1,48 @@
+package typex
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChunk(t *testing.T) {
+	tests := []struct {
+		name string
+		s    []int
+		size int
+		want [][]int
+	}{
+		{name: "nil", s: nil, size: 2, want: nil},
+		{name: "empty", s: []int{}, size: 2, want: nil},
+		{name: "even", s: []int{1, 2, 3, 4}, size: 2, want: [][]int{{1, 2}, {3, 4}}},
+		{name: "short last chunk", s: []int{1, 2, 3, 4, 5}, size: 2, want: [][]int{{1, 2}, {3, 4}, {5}}},
+		{name: "size larger than slice", s: []int{1, 2}, size: 5, want: [][]int{{1, 2}}},
+		{name: "non-positive size", s: []int{1, 2, 3}, size: 0, want: [][]int{{1, 2, 3}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Chunk(tt.s, tt.size)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, len(tt.s) == 0, Flatten(got) == nil)
+			if len(tt.s) > 0 {
+				assert.Equal(t, tt.s, Flatten(got))
+			}
+		})
+	}
+}
+
+func TestToMapAndGroupBy(t *testing.T) {
+	type user struct {
+		ID   int
+		Team string
+	}
+	users := []user{{1, "a"}, {2, "b"}, {3, "a"}, {1, "c"}}
+
+	byID := ToMap(users, func(u user) int { return u.ID })
+	assert.Equal(t, map[int]user{1: {1, "c"}, 2: {2, "b"}, 3: {3, "a"}}, byID)
+
+	byTeam := GroupBy(users, func(u user) string { return u.Team })
+	assert.Equal(t, map[string][]user{"a": {{1, "a"}, {3, "a"}}, "b": {{2, "b"}}, "c": {{1, "c"}}}, byTeam)
+}
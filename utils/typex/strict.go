@@ -0,0 +1,56 @@
+package typex
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"sync/atomic"
+)
+
+var strict atomic.Bool
+
+// SetStrict makes ToAnyE fail, rather than wrap or round, when value doesn't fit T exactly:
+// "300" into int8, "-1" into uint, "1.5" into int, "1e39" into float32, "16777217" into float32 or
+// "9007199254740993" into float64. Decimal fractions rounded to the nearest float, such as "0.1", are
+// accepted. It's off by default, ToAnyStrict is strict whatever the setting.
+func SetStrict(enabled bool) {
+	strict.Store(enabled)
+}
+
+// ToAnyStrict is ToAnyE failing when value can't be converted to T losslessly, see SetStrict
+func ToAnyStrict[T any](value string) (T, error) {
+	t, err := ToAnyE[T](value)
+	if err != nil || strict.Load() || len(value) == 0 {
+		return t, err
+	}
+	return t, checkLossless(value, reflect.ValueOf(&t).Elem())
+}
+
+// checkLossless reports an error when value, already decoded into rv, doesn't fit the type of rv exactly
+func checkLossless(value string, rv reflect.Value) error {
+	var err error
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		_, err = strconv.ParseInt(value, 10, rv.Type().Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		_, err = strconv.ParseUint(value, 10, rv.Type().Bits())
+	case reflect.Float32, reflect.Float64:
+		// decimal text such as "0.1" has no exact binary form whatever the size, rounding it isn't a loss,
+		// overflowing is (ParseFloat fails with a range error) and so is rounding an integer
+		var f float64
+		f, err = strconv.ParseFloat(value, rv.Type().Bits())
+		if err != nil {
+			break
+		}
+		if i, intErr := strconv.ParseInt(value, 10, 64); intErr == nil && (f >= math.MaxInt64 || int64(f) != i) {
+			err = fmt.Errorf("rounded to %v", rv.Interface())
+		}
+	default:
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("typex: %q doesn't fit %s losslessly: %w", value, rv.Type(), err)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package typex
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToAnyStrict(t *testing.T) {
+	_, err := ToAnyStrict[int8]("300")
+	assert.Error(t, err)
+	_, err = ToAnyStrict[uint]("-1")
+	assert.Error(t, err)
+	_, err = ToAnyStrict[int]("1.5")
+	assert.Error(t, err)
+	_, err = ToAnyStrict[float32]("16777217")
+	assert.Error(t, err)
+	_, err = ToAnyStrict[float64]("9007199254740993")
+	assert.Error(t, err)
+	_, err = ToAnyStrict[float32]("1e39")
+	assert.Error(t, err)
+	_, err = ToAnyStrict[float64]("1e400")
+	assert.Error(t, err)
+	_, err = ToAnyStrict[status]("99999999999999999999")
+	assert.Error(t, err)
+
+	assert.Equal(t, int8(-128), Must(ToAnyStrict[int8]("-128")))
+	assert.Equal(t, uint64(18446744073709551615), Must(ToAnyStrict[uint64]("18446744073709551615")))
+	assert.Equal(t, float32(0.1), Must(ToAnyStrict[float32](ToString(float32(0.1)))))
+	// decimal text rounds to the nearest float, that's not a loss
+	assert.Equal(t, float32(0.1), Must(ToAnyStrict[float32]("0.1")))
+	assert.Equal(t, float32(3.14), Must(ToAnyStrict[float32]("3.14")))
+	assert.Equal(t, 0.1, Must(ToAnyStrict[float64]("0.1")))
+	assert.Equal(t, float64(9007199254740992), Must(ToAnyStrict[float64]("9007199254740992")))
+	assert.Equal(t, "abc", Must(ToAnyStrict[string]("abc")))
+	assert.Zero(t, Must(ToAnyStrict[int]("")))
+
+	// lenient by default
+	assert.Equal(t, int8(44), Must(ToAnyE[int8]("300")))
+}
+
+func TestSetStrict(t *testing.T) {
+	SetStrict(true)
+	defer SetStrict(false)
+
+	_, err := ToAnyE[int8]("300")
+	assert.Error(t, err)
+	assert.Equal(t, int64(42), Must(ToAnyE[int64]("42")))
+	assert.Equal(t, float32(3.14), Must(ToAnyE[float32]("3.14")))
+
+	var f float32
+	assert.NoError(t, Decode("0.1", &f))
+	assert.Equal(t, float32(0.1), f)
+}
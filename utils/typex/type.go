@@ -13,6 +13,9 @@ func ToAny[T any](value string) T {
 	return t
 }
 
+// ToAnyE decodes a string produced by ToString into T. An empty value yields the zero value,
+// bool accepts the forms of strconv.ParseBool such as "true", "false", "1" and "0".
+// Numbers not fitting T wrap or round silently unless SetStrict is enabled.
 func ToAnyE[T any](value string) (T, error) {
 	var t T
 	var err error
@@ -23,6 +26,10 @@ func ToAnyE[T any](value string) (T, error) {
 	switch any(t).(type) {
 	case string:
 		t = any(value).(T)
+	case bool:
+		var v bool
+		v, err = strconv.ParseBool(value)
+		t = any(v).(T)
 	case int:
 		var v int
 		v, err = strconv.Atoi(value)
@@ -60,9 +67,15 @@ func ToAnyE[T any](value string) (T, error) {
 		v, err = strconv.Atoi(value)
 		t = any(uint32(v)).(T)
 	case uint64:
-		var v int
-		v, err = strconv.Atoi(value)
-		t = any(uint64(v)).(T)
+		var v uint64
+		v, err = strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			// values above math.MaxInt64 parse above, negative ones keep wrapping as they always did
+			var i int
+			i, err = strconv.Atoi(value)
+			v = uint64(i)
+		}
+		t = any(v).(T)
 	case float32:
 		var v float64
 		v, err = strconv.ParseFloat(value, 64)
@@ -71,6 +84,8 @@ func ToAnyE[T any](value string) (T, error) {
 		var v float64
 		v, err = strconv.ParseFloat(value, 64)
 		t = any(v).(T)
+	case []byte:
+		t = any([]byte(value)).(T)
 	case []any:
 		var v []any
 		err = sonic.UnmarshalString(value, &v)
@@ -80,11 +95,48 @@ func ToAnyE[T any](value string) (T, error) {
 		err = sonic.UnmarshalString(value, &v)
 		t = any(v).(T)
 	default:
-		err = sonic.UnmarshalString(value, &t)
+		if !parseKind(value, reflect.ValueOf(&t).Elem(), &err) {
+			err = sonic.UnmarshalString(value, &t)
+		}
+	}
+	if err == nil && strict.Load() {
+		err = checkLossless(value, reflect.ValueOf(&t).Elem())
 	}
 	return t, err
 }
 
+// parseKind parses value into rv when its kind is a basic one, so defined types such as
+// `type Status int` or `type Flag bool` decode like their underlying type.
+// It reports false when the kind isn't handled.
+func parseKind(value string, rv reflect.Value, err *error) bool {
+	switch rv.Kind() {
+	case reflect.Bool:
+		var v bool
+		v, *err = strconv.ParseBool(value)
+		rv.SetBool(v)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var v int64
+		v, *err = strconv.ParseInt(value, 10, rv.Type().Bits())
+		rv.SetInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var v uint64
+		v, *err = strconv.ParseUint(value, 10, rv.Type().Bits())
+		rv.SetUint(v)
+	case reflect.Float32, reflect.Float64:
+		var v float64
+		v, *err = strconv.ParseFloat(value, rv.Type().Bits())
+		rv.SetFloat(v)
+	case reflect.String:
+		rv.SetString(value)
+	default:
+		return false
+	}
+	return true
+}
+
+// ToString encodes value as a string that ToAnyE can decode back, bool is encoded as "true"/"false".
+// Types implementing fmt.Stringer are encoded with String(), so a defined integer type with a
+// String method only round-trips if it can also be decoded from that form.
 func ToString(value any) string {
 	switch v := value.(type) {
 	case fmt.Stringer:
@@ -140,3 +192,28 @@ func ToString(value any) string {
 		return s
 	}
 }
+
+// Decode is ToAnyE for a type only known at runtime: it decodes value into the value ptr points to,
+// e.g. a struct field reached through reflection. An empty value leaves it untouched.
+func Decode(value string, ptr any) error {
+	rv := reflect.ValueOf(ptr)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("typex: Decode needs a non nil pointer, got %T", ptr)
+	}
+	if len(value) == 0 {
+		return nil
+	}
+
+	var err error
+	elem := rv.Elem()
+	switch {
+	case elem.Kind() == reflect.Slice && elem.Type().Elem().Kind() == reflect.Uint8:
+		elem.SetBytes([]byte(value))
+	case !parseKind(value, elem, &err):
+		err = sonic.UnmarshalString(value, ptr)
+	}
+	if err == nil && strict.Load() {
+		err = checkLossless(value, elem)
+	}
+	return err
+}
@@ -0,0 +1,100 @@
+package typex
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type status int
+
+type flag bool
+
+type name string
+
+func TestToAnyE_Bool(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    bool
+		wantErr bool
+	}{
+		{name: "true", value: "true", want: true},
+		{name: "false", value: "false", want: false},
+		{name: "one", value: "1", want: true},
+		{name: "zero", value: "0", want: false},
+		{name: "empty", value: "", want: false},
+		{name: "invalid", value: "yes", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToAnyE[bool](tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestToString_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		str   string
+		back  func(string) (any, error)
+	}{
+		{name: "bool true", value: true, str: "true", back: decode[bool]},
+		{name: "bool false", value: false, str: "false", back: decode[bool]},
+		{name: "defined int", value: status(3), str: "3", back: decode[status]},
+		{name: "defined negative int", value: status(-7), str: "-7", back: decode[status]},
+		{name: "defined bool", value: flag(true), str: "true", back: decode[flag]},
+		{name: "defined string", value: name("alice"), str: "alice", back: decode[name]},
+		{name: "int64", value: int64(1) << 40, str: "1099511627776", back: decode[int64]},
+		{name: "bytes", value: []byte{0x00, 0x1f, 'a'}, str: "\x00\x1fa", back: decode[[]byte]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := ToString(tt.value)
+			assert.Equal(t, tt.str, s)
+			got, err := tt.back(s)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.value, got)
+		})
+	}
+}
+
+func TestToAnyE_DefinedIntOverflow(t *testing.T) {
+	type small int8
+	_, err := ToAnyE[small]("300")
+	assert.Error(t, err)
+}
+
+func decode[T any](s string) (any, error) {
+	return ToAnyE[T](s)
+}
+
+func TestDecode(t *testing.T) {
+	var n int32
+	assert.NoError(t, Decode("42", &n))
+	assert.Equal(t, int32(42), n)
+
+	var s status
+	assert.NoError(t, Decode("7", &s))
+	assert.Equal(t, status(7), s)
+
+	var tags []string
+	assert.NoError(t, Decode(`["a","b"]`, &tags))
+	assert.Equal(t, []string{"a", "b"}, tags)
+
+	var raw []byte
+	assert.NoError(t, Decode("bytes", &raw))
+	assert.Equal(t, []byte("bytes"), raw)
+
+	assert.Error(t, Decode("x", &n))
+	assert.Error(t, Decode("1", n))
+}
@@ -0,0 +1,25 @@
+package typex
+
+// Zero returns the zero value of T, the value redisx returns for missing keys and fields
+func Zero[T any]() T {
+	var zero T
+	return zero
+}
+
+// IsZero reports whether v is the zero value of T
+func IsZero[T comparable](v T) bool {
+	var zero T
+	return v == zero
+}
+
+// Coalesce returns the first of vals that isn't the zero value of T, or the zero value when they all are,
+// e.g. to layer defaults under a config: typex.Coalesce(cfg.PoolSize, 1000)
+func Coalesce[T comparable](vals ...T) T {
+	var zero T
+	for _, v := range vals {
+		if v != zero {
+			return v
+		}
+	}
+	return zero
+}
@@ -0,0 +1,48 @@
+package typex
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestZero(t *testing.T) {
+	assert.Equal(t, 0, Zero[int]())
+	assert.Equal(t, "", Zero[string]())
+	assert.Nil(t, Zero[*int]())
+	assert.Nil(t, Zero[[]string]())
+	assert.Nil(t, Zero[error]())
+	assert.Equal(t, time.Time{}, Zero[time.Time]())
+}
+
+func TestIsZero(t *testing.T) {
+	assert.True(t, IsZero(0))
+	assert.False(t, IsZero(-1))
+	assert.True(t, IsZero(""))
+	assert.False(t, IsZero(" "))
+	assert.True(t, IsZero[*int](nil))
+	assert.False(t, IsZero(new(int)))
+	assert.True(t, IsZero(time.Duration(0)))
+	assert.True(t, IsZero(status(0)))
+
+	type addr struct{ Host string }
+	assert.True(t, IsZero(addr{}))
+	assert.False(t, IsZero(addr{Host: "a"}))
+
+	var err error
+	assert.True(t, IsZero(err))
+}
+
+func TestCoalesce(t *testing.T) {
+	assert.Equal(t, "env", Coalesce("", "env", "default"))
+	assert.Equal(t, 1000, Coalesce(0, 1000))
+	assert.Equal(t, 5*time.Second, Coalesce(time.Duration(0), 5*time.Second))
+	assert.Zero(t, Coalesce[int]())
+	assert.Zero(t, Coalesce("", ""))
+
+	type addr struct{ Host string }
+	assert.Equal(t, addr{Host: "b"}, Coalesce(addr{}, addr{Host: "b"}))
+
+	assert.Zero(t, testing.AllocsPerRun(100, func() { _ = Coalesce(0, 0, 3) }))
+}